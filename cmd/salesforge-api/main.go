@@ -11,9 +11,14 @@ import (
 	"os/signal"
 	"salesforge-api/internal/api"
 	"salesforge-api/internal/config"
+	"salesforge-api/internal/mail"
+	"salesforge-api/internal/middleware"
 	"salesforge-api/internal/persistence"
 	"salesforge-api/internal/psql"
+	"salesforge-api/internal/schedule"
+	"salesforge-api/internal/secrets"
 	"salesforge-api/internal/service"
+	"salesforge-api/internal/tracking"
 	"syscall"
 	"time"
 )
@@ -58,12 +63,39 @@ func main() {
 	l.Info("connected to database")
 	defer db.Close()
 
+	// Secrets.
+	cipher, err := secrets.NewCipher(cfg.Secrets)
+	if err != nil {
+		l.Fatal("failed to load secrets keys", zap.Error(err))
+	}
+
 	// Services.
 	sequenceRepository := persistence.NewSequenceRepository(db)
 	sequenceService := service.NewSequenceService(sequenceRepository)
+	contactRepository := persistence.NewContactRepository(db)
+	contactService := service.NewContactService(contactRepository)
+	enrollmentRepository := persistence.NewEnrollmentRepository(db)
+	enrollmentService := service.NewEnrollmentService(sequenceRepository, enrollmentRepository)
+	mailboxRepository := persistence.NewMailboxRepository(db, cipher)
+	mailboxService := service.NewMailboxService(mailboxRepository)
+	previewService := service.NewPreviewService(sequenceRepository, contactRepository)
+	apiKeyRepository := persistence.NewAPIKeyRepository(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository)
+	tracker := tracking.NewTracker(cfg.Tracking)
+	eventRepository := persistence.NewEventRepository(db)
+	trackingService := service.NewTrackingService(eventRepository, tracker)
+
+	// Authentication.
+	var verifier *middleware.JWTVerifier
+	if cfg.Server.JWTAuthentication {
+		verifier, err = middleware.NewJWTVerifier(cfg.Server.JWT)
+		if err != nil {
+			l.Fatal("failed to create jwt verifier", zap.Error(err))
+		}
+	}
 
 	// Main server.
-	server := api.NewServer(cfg.Server, sequenceService, l)
+	server := api.NewServer(cfg.Server, verifier, sequenceService, contactService, enrollmentService, mailboxService, previewService, apiKeyService, trackingService, l)
 	go func() {
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			l.Fatal("server failed", zap.Error(err))
@@ -80,6 +112,20 @@ func main() {
 	}()
 	l.Info("health check server started", zap.Int("port", cfg.Server.HealthcheckPort))
 
+	// Scheduler.
+	var scheduler *schedule.Scheduler
+	if cfg.Scheduler.Enabled {
+		sender, err := mail.NewSender(cfg.Smtp)
+		if err != nil {
+			l.Fatal("failed to create sender", zap.Error(err))
+		}
+		sendRepository := persistence.NewSendRepository(db, cipher)
+		delivery := mail.NewDelivery(cfg.Smtp, sender, tracker)
+		scheduler = schedule.NewScheduler(cfg.Scheduler, sendRepository, delivery, l)
+		scheduler.Start()
+		l.Info("scheduler started", zap.Int("interval_seconds", cfg.Scheduler.IntervalSeconds))
+	}
+
 	// Listen to interrupts.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, interruptSignals...)
@@ -96,6 +142,11 @@ func main() {
 	if err := healthCheckServer.Shutdown(ctx); err != nil {
 		l.Fatal("health check server shutdown failed", zap.Error(err))
 	}
+	if scheduler != nil {
+		if err := scheduler.Shutdown(ctx); err != nil {
+			l.Fatal("scheduler shutdown failed", zap.Error(err))
+		}
+	}
 
 	l.Info("server exited properly")
 }
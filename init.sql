@@ -6,9 +6,12 @@ CREATE TABLE IF NOT EXISTS sequences
     updated_at                      BIGINT DEFAULT NULL,
     sequence_name                   VARCHAR(255) NOT NULL,
     sequence_open_tracking_enabled  BOOLEAN      NOT NULL,
-    sequence_click_tracking_enabled BOOLEAN      NOT NULL
+    sequence_click_tracking_enabled BOOLEAN      NOT NULL,
+    archived_at                     BIGINT DEFAULT NULL
 );
 
+CREATE INDEX IF NOT EXISTS sequences_account_created_idx ON sequences (account_id, created_at, sequence_id);
+
 CREATE TABLE IF NOT EXISTS steps
 (
     account_id          BIGINT       NOT NULL,
@@ -21,9 +24,153 @@ CREATE TABLE IF NOT EXISTS steps
     wait_days           INT          NOT NULL,
     eligible_start_time BIGINT       NOT NULL,
     eligible_end_time   BIGINT       NOT NULL,
-    FOREIGN KEY (sequence_id) REFERENCES sequences (sequence_id)
+    step_order          INT          NOT NULL,
+    auto_promote_after  INT          NOT NULL DEFAULT 0,
+    promoted_variant_id BIGINT DEFAULT NULL,
+    FOREIGN KEY (sequence_id) REFERENCES sequences (sequence_id) ON DELETE CASCADE,
+    -- Deferred so that steps can be shifted or reordered within a transaction.
+    UNIQUE (sequence_id, step_order) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE TABLE IF NOT EXISTS step_variants
+(
+    variant_id         SERIAL PRIMARY KEY,
+    account_id         BIGINT       NOT NULL,
+    step_id            BIGINT       NOT NULL,
+    created_at         BIGINT       NOT NULL,
+    updated_at         BIGINT DEFAULT NULL,
+    step_email_subject VARCHAR(255) NOT NULL,
+    step_email_body    TEXT         NOT NULL,
+    weight             INT          NOT NULL CHECK (weight >= 0),
+    FOREIGN KEY (step_id) REFERENCES steps (step_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS step_variants_step_idx ON step_variants (step_id);
+
+CREATE TABLE IF NOT EXISTS contacts
+(
+    contact_id    SERIAL PRIMARY KEY,
+    account_id    BIGINT       NOT NULL,
+    created_at    BIGINT       NOT NULL,
+    updated_at    BIGINT DEFAULT NULL,
+    email         VARCHAR(320) NOT NULL,
+    first_name    VARCHAR(255) NOT NULL DEFAULT '',
+    last_name     VARCHAR(255) NOT NULL DEFAULT '',
+    company       VARCHAR(255) NOT NULL DEFAULT '',
+    timezone      VARCHAR(64)  NOT NULL DEFAULT 'UTC',
+    custom_fields JSONB        NOT NULL DEFAULT '{}',
+    UNIQUE (account_id, email)
 );
 
+CREATE TABLE IF NOT EXISTS enrollments
+(
+    enrollment_id   SERIAL PRIMARY KEY,
+    account_id      BIGINT      NOT NULL,
+    sequence_id     BIGINT      NOT NULL,
+    contact_id      BIGINT      NOT NULL,
+    created_at      BIGINT      NOT NULL,
+    updated_at      BIGINT DEFAULT NULL,
+    status          VARCHAR(32) NOT NULL,
+    current_step    INT         NOT NULL DEFAULT 0,
+    current_step_id BIGINT DEFAULT NULL,
+    next_send_at    BIGINT DEFAULT NULL,
+    FOREIGN KEY (sequence_id) REFERENCES sequences (sequence_id) ON DELETE CASCADE,
+    FOREIGN KEY (contact_id) REFERENCES contacts (contact_id) ON DELETE CASCADE,
+    -- Set to NULL when the step is removed; current_step then tells where to carry on.
+    FOREIGN KEY (current_step_id) REFERENCES steps (step_id) ON DELETE SET NULL,
+    UNIQUE (sequence_id, contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS enrollments_due_idx ON enrollments (next_send_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS mailboxes
+(
+    mailbox_id            SERIAL PRIMARY KEY,
+    account_id            BIGINT       NOT NULL,
+    created_at            BIGINT       NOT NULL,
+    updated_at            BIGINT DEFAULT NULL,
+    email                 VARCHAR(320) NOT NULL,
+    from_name             VARCHAR(255) NOT NULL DEFAULT '',
+    smtp_host             VARCHAR(255) NOT NULL,
+    smtp_port             INT          NOT NULL,
+    smtp_username         VARCHAR(255) NOT NULL DEFAULT '',
+    smtp_password         TEXT         NOT NULL DEFAULT '', -- Encrypted
+    daily_limit           INT          NOT NULL CHECK (daily_limit > 0),
+    warmup_start_limit    INT          NOT NULL DEFAULT 0,
+    warmup_daily_increase INT          NOT NULL DEFAULT 0,
+    consecutive_failures  INT          NOT NULL DEFAULT 0,
+    last_failed_at        BIGINT DEFAULT NULL,
+    sent_day              BIGINT       NOT NULL DEFAULT 0,
+    sent_count            INT          NOT NULL DEFAULT 0,
+    UNIQUE (account_id, email)
+);
+
+CREATE TABLE IF NOT EXISTS sequence_mailboxes
+(
+    sequence_id BIGINT NOT NULL,
+    mailbox_id  BIGINT NOT NULL,
+    PRIMARY KEY (sequence_id, mailbox_id),
+    FOREIGN KEY (sequence_id) REFERENCES sequences (sequence_id) ON DELETE CASCADE,
+    FOREIGN KEY (mailbox_id) REFERENCES mailboxes (mailbox_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS sends
+(
+    send_id       SERIAL PRIMARY KEY,
+    account_id    BIGINT      NOT NULL,
+    enrollment_id BIGINT      NOT NULL,
+    step_id       BIGINT      NOT NULL,
+    mailbox_id    BIGINT DEFAULT NULL,
+    variant_id    BIGINT DEFAULT NULL,
+    created_at    BIGINT      NOT NULL,
+    updated_at    BIGINT DEFAULT NULL,
+    status        VARCHAR(32) NOT NULL,
+    attempts      INT         NOT NULL DEFAULT 0,
+    error         TEXT        NOT NULL DEFAULT '',
+    message_id    TEXT        NOT NULL DEFAULT '',
+    sent_at       BIGINT DEFAULT NULL,
+    FOREIGN KEY (enrollment_id) REFERENCES enrollments (enrollment_id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES steps (step_id) ON DELETE CASCADE,
+    FOREIGN KEY (mailbox_id) REFERENCES mailboxes (mailbox_id) ON DELETE SET NULL,
+    FOREIGN KEY (variant_id) REFERENCES step_variants (variant_id) ON DELETE SET NULL,
+    UNIQUE (enrollment_id, step_id)
+);
+
+CREATE INDEX IF NOT EXISTS sends_message_idx ON sends (account_id, message_id) WHERE message_id <> '';
+
+CREATE TABLE IF NOT EXISTS events
+(
+    event_id      SERIAL PRIMARY KEY,
+    account_id    BIGINT      NOT NULL,
+    sequence_id   BIGINT      NOT NULL,
+    enrollment_id BIGINT      NOT NULL,
+    step_id       BIGINT      NOT NULL,
+    type          VARCHAR(32) NOT NULL,
+    created_at    BIGINT      NOT NULL,
+    user_agent    TEXT        NOT NULL DEFAULT '',
+    ip_address    VARCHAR(64) NOT NULL DEFAULT '',
+    url           TEXT        NOT NULL DEFAULT '',
+    FOREIGN KEY (enrollment_id) REFERENCES enrollments (enrollment_id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES steps (step_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS events_sequence_idx ON events (sequence_id, type);
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    api_key_id   SERIAL PRIMARY KEY,
+    account_id   BIGINT       NOT NULL,
+    created_at   BIGINT       NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(32)  NOT NULL UNIQUE,
+    key_hash     VARCHAR(64)  NOT NULL,
+    scopes       TEXT[]       NOT NULL,
+    last_used_at BIGINT DEFAULT NULL,
+    revoked_at   BIGINT DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_account_idx ON api_keys (account_id);
+
 -- Insert sample data into sequences table
 INSERT INTO sequences (account_id, created_at, sequence_name, sequence_open_tracking_enabled,
                        sequence_click_tracking_enabled)
@@ -32,9 +179,14 @@ VALUES (1, 1633036800, 'Welcome Sequence', true, true),
 
 -- Insert sample data into steps table
 INSERT INTO steps (account_id, sequence_id, created_at, step_email_subject, step_email_body, wait_days,
-                   eligible_start_time, eligible_end_time)
-VALUES (1, 1, 1633036800, 'Welcome to our service', 'Thank you for joining us!', 1, 1633036800, 1633123200),
+                   eligible_start_time, eligible_end_time, step_order)
+VALUES (1, 1, 1633036800, 'Welcome to our service', 'Thank you for joining us!', 1, 1633036800, 1633123200, 1),
        (1, 1, 1633123200, 'Getting Started', 'Here are some tips to get started.', 2, 1633123200,
-        1633209600),
-       (2, 2, 1633123200, 'Welcome to the team', 'We are excited to have you!', 1, 1633123200, 1633209600),
-       (2, 2, 1633209600, 'Next Steps', 'Here is what you need to do next.', 3, 1633209600, 1633296000);
+        1633209600, 2),
+       (2, 2, 1633123200, 'Welcome to the team', 'We are excited to have you!', 1, 1633123200, 1633209600, 1),
+       (2, 2, 1633209600, 'Next Steps', 'Here is what you need to do next.', 3, 1633209600, 1633296000, 2);
+
+-- Insert sample data into contacts table
+INSERT INTO contacts (account_id, created_at, email, first_name, last_name, company, timezone, custom_fields)
+VALUES (1, 1633036800, 'jane.doe@example.com', 'Jane', 'Doe', 'Acme', 'Europe/Zagreb', '{"title": "CTO"}'),
+       (2, 1633123200, 'john.smith@example.com', 'John', 'Smith', 'Globex', 'America/New_York', '{}');
@@ -0,0 +1,101 @@
+package apikey
+
+import (
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	logger        *zap.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+func (ah *APIKeyHandler) AddAPIKey(w http.ResponseWriter, r *http.Request) {
+	ah.logger.Info("AddAPIKey request received")
+	addAPIKeyRequest, err := NewAddAPIKeyRequestFromHttpRequest(r)
+	if err != nil {
+		ah.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	apiKey, key, err := ah.apiKeyService.AddAPIKey(r.Context(), addAPIKeyRequest)
+	if err != nil {
+		ah.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddAPIKeyResponse{
+		APIKey: *apiKey,
+		Key:    key,
+		Status: "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ah *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ah.logger.Info("ListAPIKeys request received")
+	listAPIKeysRequest, err := NewListAPIKeysRequestFromHttpRequest(r)
+	if err != nil {
+		ah.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	apiKeys, err := ah.apiKeyService.ListAPIKeys(r.Context(), listAPIKeysRequest)
+	if err != nil {
+		ah.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.ListAPIKeysResponse{
+		APIKeys: apiKeys,
+		Status:  "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ah *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ah.logger.Info("RevokeAPIKey request received")
+	revokeAPIKeyRequest, err := NewRevokeAPIKeyRequestFromHttpRequest(r)
+	if err != nil {
+		ah.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	apiKeyId, err := ah.apiKeyService.RevokeAPIKey(r.Context(), revokeAPIKeyRequest)
+	if err != nil {
+		ah.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.RevokeAPIKeyResponse{
+		APIKeyID: apiKeyId,
+		Status:   "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
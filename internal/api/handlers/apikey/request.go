@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"strconv"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+func NewAddAPIKeyRequestFromHttpRequest(r *http.Request) (*models.AddAPIKeyRequest, error) {
+	addAPIKeyRequest := &models.AddAPIKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(addAPIKeyRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	isValid, invalidFields := addAPIKeyRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return addAPIKeyRequest, nil
+}
+
+func NewListAPIKeysRequestFromHttpRequest(r *http.Request) (*models.ListAPIKeysRequest, error) {
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"account_id"})
+	}
+
+	listAPIKeysRequest := &models.ListAPIKeysRequest{
+		AccountID: accountId,
+	}
+
+	isValid, invalidFields := listAPIKeysRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return listAPIKeysRequest, nil
+}
+
+func NewRevokeAPIKeyRequestFromHttpRequest(r *http.Request) (*models.RevokeAPIKeyRequest, error) {
+	var invalidFields []string
+	apiKeyId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "api_key_id")
+	}
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	revokeAPIKeyRequest := &models.RevokeAPIKeyRequest{
+		AccountID: accountId,
+		APIKeyID:  apiKeyId,
+	}
+
+	isValid, invalidFields := revokeAPIKeyRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return revokeAPIKeyRequest, nil
+}
@@ -0,0 +1,155 @@
+package contact
+
+import (
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+)
+
+type ContactHandler struct {
+	contactService service.ContactService
+	logger         *zap.Logger
+}
+
+func NewContactHandler(contactService service.ContactService, logger *zap.Logger) *ContactHandler {
+	return &ContactHandler{
+		contactService: contactService,
+		logger:         logger,
+	}
+}
+
+func (ch *ContactHandler) AddContact(w http.ResponseWriter, r *http.Request) {
+	ch.logger.Info("AddContact request received")
+	addContactRequest, err := NewAddContactRequestFromHttpRequest(r)
+	if err != nil {
+		ch.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	contactId, err := ch.contactService.AddContact(r.Context(), &addContactRequest.Contact)
+	if err != nil {
+		ch.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddContactResponse{
+		ContactID: contactId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ch *ContactHandler) GetContact(w http.ResponseWriter, r *http.Request) {
+	ch.logger.Info("GetContact request received")
+	getContactRequest, err := NewGetContactRequestFromHttpRequest(r)
+	if err != nil {
+		ch.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	contact, err := ch.contactService.GetContact(r.Context(), getContactRequest)
+	if err != nil {
+		ch.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.GetContactResponse{
+		Contact: *contact,
+		Status:  "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ch *ContactHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	ch.logger.Info("ListContacts request received")
+	listContactsRequest, err := NewListContactsRequestFromHttpRequest(r)
+	if err != nil {
+		ch.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	contacts, next, err := ch.contactService.ListContacts(r.Context(), listContactsRequest)
+	if err != nil {
+		ch.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.ListContactsResponse{
+		Contacts: contacts,
+		Status:   "ok",
+	}
+	if next != nil {
+		res.NextCursor = next.Encode()
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ch *ContactHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	ch.logger.Info("UpdateContact request received")
+	updateContactRequest, err := NewUpdateContactRequestFromHttpRequest(r)
+	if err != nil {
+		ch.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	contactId, err := ch.contactService.UpdateContact(r.Context(), updateContactRequest)
+	if err != nil {
+		ch.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.UpdateContactResponse{
+		ContactID: contactId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (ch *ContactHandler) DeleteContact(w http.ResponseWriter, r *http.Request) {
+	ch.logger.Info("DeleteContact request received")
+	deleteContactRequest, err := NewDeleteContactRequestFromHttpRequest(r)
+	if err != nil {
+		ch.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	contactId, err := ch.contactService.DeleteContact(r.Context(), deleteContactRequest)
+	if err != nil {
+		ch.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.DeleteContactResponse{
+		ContactID: contactId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
@@ -0,0 +1,161 @@
+package contact
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"strconv"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+// parseIds reads the contact id from the path and the account id from the
+// query string.
+func parseIds(r *http.Request) (accountId int64, contactId int64, invalidFields []string) {
+	contactId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "contact_id")
+	}
+	accountId, err = strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	return accountId, contactId, invalidFields
+}
+
+func NewAddContactRequestFromHttpRequest(r *http.Request) (*models.AddContactRequest, error) {
+	addContactRequest := &models.AddContactRequest{}
+	err := json.NewDecoder(r.Body).Decode(addContactRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	addContactRequest.Email = models.NormalizeEmail(addContactRequest.Email)
+	if addContactRequest.Timezone == "" {
+		addContactRequest.Timezone = models.DefaultContactTimezone
+	}
+
+	isValid, invalidFields := addContactRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return addContactRequest, nil
+}
+
+func NewGetContactRequestFromHttpRequest(r *http.Request) (*models.GetContactRequest, error) {
+	accountId, contactId, invalidFields := parseIds(r)
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	getContactRequest := &models.GetContactRequest{
+		AccountID: accountId,
+		ContactID: contactId,
+	}
+
+	isValid, invalidFields := getContactRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return getContactRequest, nil
+}
+
+func NewListContactsRequestFromHttpRequest(r *http.Request) (*models.ListContactsRequest, error) {
+	query := r.URL.Query()
+	listContactsRequest := &models.ListContactsRequest{
+		Email: query.Get("email"),
+		Limit: models.DefaultListLimit,
+	}
+	var invalidFields []string
+
+	accountId, err := strconv.ParseInt(query.Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	listContactsRequest.AccountID = accountId
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			invalidFields = append(invalidFields, "limit")
+		}
+		listContactsRequest.Limit = limit
+	}
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := models.DecodeContactCursor(v)
+		if err != nil {
+			invalidFields = append(invalidFields, "cursor")
+		}
+		listContactsRequest.Cursor = cursor
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	isValid, invalidFields := listContactsRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return listContactsRequest, nil
+}
+
+func NewUpdateContactRequestFromHttpRequest(r *http.Request) (*models.UpdateContactRequest, error) {
+	updateContactRequest := &models.UpdateContactRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateContactRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	contactId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"contact_id"})
+	}
+	updateContactRequest.ContactID = contactId
+
+	if updateContactRequest.Email != nil {
+		email := models.NormalizeEmail(*updateContactRequest.Email)
+		updateContactRequest.Email = &email
+	}
+
+	isValid, invalidFields := updateContactRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return updateContactRequest, nil
+}
+
+func NewDeleteContactRequestFromHttpRequest(r *http.Request) (*models.DeleteContactRequest, error) {
+	accountId, contactId, invalidFields := parseIds(r)
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	deleteContactRequest := &models.DeleteContactRequest{
+		AccountID: accountId,
+		ContactID: contactId,
+	}
+
+	isValid, invalidFields := deleteContactRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return deleteContactRequest, nil
+}
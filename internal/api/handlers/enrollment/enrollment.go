@@ -0,0 +1,111 @@
+package enrollment
+
+import (
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+)
+
+type EnrollmentHandler struct {
+	enrollmentService service.EnrollmentService
+	logger            *zap.Logger
+}
+
+func NewEnrollmentHandler(enrollmentService service.EnrollmentService, logger *zap.Logger) *EnrollmentHandler {
+	return &EnrollmentHandler{
+		enrollmentService: enrollmentService,
+		logger:            logger,
+	}
+}
+
+func (eh *EnrollmentHandler) AddEnrollments(w http.ResponseWriter, r *http.Request) {
+	eh.logger.Info("AddEnrollments request received")
+	addEnrollmentsRequest, err := NewAddEnrollmentsRequestFromHttpRequest(r)
+	if err != nil {
+		eh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	enrollments, skippedContactIds, err := eh.enrollmentService.AddEnrollments(r.Context(), addEnrollmentsRequest)
+	if err != nil {
+		eh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddEnrollmentsResponse{
+		SequenceID:        addEnrollmentsRequest.SequenceID,
+		Enrollments:       enrollments,
+		SkippedContactIDs: skippedContactIds,
+		Status:            "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (eh *EnrollmentHandler) PauseEnrollment(w http.ResponseWriter, r *http.Request) {
+	eh.logger.Info("PauseEnrollment request received")
+	eh.updateEnrollment(w, r, models.EnrollmentStatusPaused)
+}
+
+func (eh *EnrollmentHandler) ResumeEnrollment(w http.ResponseWriter, r *http.Request) {
+	eh.logger.Info("ResumeEnrollment request received")
+	eh.updateEnrollment(w, r, models.EnrollmentStatusActive)
+}
+
+func (eh *EnrollmentHandler) updateEnrollment(w http.ResponseWriter, r *http.Request, status string) {
+	updateEnrollmentRequest, err := NewUpdateEnrollmentRequestFromHttpRequest(r, status)
+	if err != nil {
+		eh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	enrollmentId, err := eh.enrollmentService.UpdateEnrollment(r.Context(), updateEnrollmentRequest)
+	if err != nil {
+		eh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.UpdateEnrollmentResponse{
+		EnrollmentID: enrollmentId,
+		Status:       "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (eh *EnrollmentHandler) DeleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	eh.logger.Info("DeleteEnrollment request received")
+	deleteEnrollmentRequest, err := NewDeleteEnrollmentRequestFromHttpRequest(r)
+	if err != nil {
+		eh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	enrollmentId, err := eh.enrollmentService.DeleteEnrollment(r.Context(), deleteEnrollmentRequest)
+	if err != nil {
+		eh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.DeleteEnrollmentResponse{
+		EnrollmentID: enrollmentId,
+		Status:       "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
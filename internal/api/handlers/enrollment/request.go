@@ -0,0 +1,95 @@
+package enrollment
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"strconv"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+func NewAddEnrollmentsRequestFromHttpRequest(r *http.Request) (*models.AddEnrollmentsRequest, error) {
+	addEnrollmentsRequest := &models.AddEnrollmentsRequest{}
+	err := json.NewDecoder(r.Body).Decode(addEnrollmentsRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	sequenceId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"sequence_id"})
+	}
+	addEnrollmentsRequest.SequenceID = sequenceId
+
+	isValid, invalidFields := addEnrollmentsRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return addEnrollmentsRequest, nil
+}
+
+// NewUpdateEnrollmentRequestFromHttpRequest builds a request moving the
+// enrollment to status, which comes from the route rather than the payload.
+func NewUpdateEnrollmentRequestFromHttpRequest(r *http.Request, status string) (*models.UpdateEnrollmentRequest, error) {
+	updateEnrollmentRequest := &models.UpdateEnrollmentRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateEnrollmentRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	enrollmentId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"enrollment_id"})
+	}
+	updateEnrollmentRequest.EnrollmentID = enrollmentId
+	updateEnrollmentRequest.Status = status
+
+	isValid, invalidFields := updateEnrollmentRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return updateEnrollmentRequest, nil
+}
+
+func NewDeleteEnrollmentRequestFromHttpRequest(r *http.Request) (*models.DeleteEnrollmentRequest, error) {
+	var invalidFields []string
+	enrollmentId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "enrollment_id")
+	}
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	deleteEnrollmentRequest := &models.DeleteEnrollmentRequest{
+		AccountID:    accountId,
+		EnrollmentID: enrollmentId,
+	}
+
+	isValid, invalidFields := deleteEnrollmentRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return deleteEnrollmentRequest, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"go.uber.org/zap"
 	"net/http"
+	"salesforge-api/internal/errors"
 )
 
 type HealthCheckHandler struct {
@@ -18,7 +19,8 @@ func NewHealthCheckHandler(db *sql.DB, logger *zap.Logger) *HealthCheckHandler {
 func (h *HealthCheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Healthcheck request received")
 	if err := h.DB.Ping(); err != nil {
-		http.Error(w, "Database connection failed", http.StatusInternalServerError)
+		h.logger.Error("database ping failed", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,206 @@
+package mailbox
+
+import (
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+)
+
+type MailboxHandler struct {
+	mailboxService service.MailboxService
+	logger         *zap.Logger
+}
+
+func NewMailboxHandler(mailboxService service.MailboxService, logger *zap.Logger) *MailboxHandler {
+	return &MailboxHandler{
+		mailboxService: mailboxService,
+		logger:         logger,
+	}
+}
+
+func (mh *MailboxHandler) AddMailbox(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("AddMailbox request received")
+	addMailboxRequest, err := NewAddMailboxRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailboxId, err := mh.mailboxService.AddMailbox(r.Context(), &addMailboxRequest.Mailbox)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddMailboxResponse{
+		MailboxID: mailboxId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) GetMailbox(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("GetMailbox request received")
+	getMailboxRequest, err := NewGetMailboxRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailbox, err := mh.mailboxService.GetMailbox(r.Context(), getMailboxRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.GetMailboxResponse{
+		Mailbox: *mailbox,
+		Status:  "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) ListMailboxes(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("ListMailboxes request received")
+	listMailboxesRequest, err := NewListMailboxesRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailboxes, err := mh.mailboxService.ListMailboxes(r.Context(), listMailboxesRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.ListMailboxesResponse{
+		Mailboxes: mailboxes,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) UpdateMailbox(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("UpdateMailbox request received")
+	updateMailboxRequest, err := NewUpdateMailboxRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailboxId, err := mh.mailboxService.UpdateMailbox(r.Context(), updateMailboxRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.UpdateMailboxResponse{
+		MailboxID: mailboxId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) DeleteMailbox(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("DeleteMailbox request received")
+	deleteMailboxRequest, err := NewDeleteMailboxRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailboxId, err := mh.mailboxService.DeleteMailbox(r.Context(), deleteMailboxRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.DeleteMailboxResponse{
+		MailboxID: mailboxId,
+		Status:    "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) GetSequenceMailboxes(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("GetSequenceMailboxes request received")
+	getSequenceMailboxesRequest, err := NewGetSequenceMailboxesRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	mailboxIds, err := mh.mailboxService.GetSequenceMailboxes(r.Context(), getSequenceMailboxesRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.SequenceMailboxesResponse{
+		SequenceID: getSequenceMailboxesRequest.SequenceID,
+		MailboxIDs: mailboxIds,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (mh *MailboxHandler) SetSequenceMailboxes(w http.ResponseWriter, r *http.Request) {
+	mh.logger.Info("SetSequenceMailboxes request received")
+	setSequenceMailboxesRequest, err := NewSetSequenceMailboxesRequestFromHttpRequest(r)
+	if err != nil {
+		mh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequenceId, err := mh.mailboxService.SetSequenceMailboxes(r.Context(), setSequenceMailboxesRequest)
+	if err != nil {
+		mh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.SequenceMailboxesResponse{
+		SequenceID: sequenceId,
+		MailboxIDs: setSequenceMailboxesRequest.MailboxIDs,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
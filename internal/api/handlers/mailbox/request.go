@@ -0,0 +1,171 @@
+package mailbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"strconv"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+// parseIds reads the id from the path, reported as idField when invalid,
+// and the account id from the query string.
+func parseIds(r *http.Request, idField string) (accountId int64, id int64, invalidFields []string) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, idField)
+	}
+	accountId, err = strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	return accountId, id, invalidFields
+}
+
+func NewAddMailboxRequestFromHttpRequest(r *http.Request) (*models.AddMailboxRequest, error) {
+	addMailboxRequest := &models.AddMailboxRequest{}
+	err := json.NewDecoder(r.Body).Decode(addMailboxRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	addMailboxRequest.Email = models.NormalizeEmail(addMailboxRequest.Email)
+
+	isValid, invalidFields := addMailboxRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return addMailboxRequest, nil
+}
+
+func NewGetMailboxRequestFromHttpRequest(r *http.Request) (*models.GetMailboxRequest, error) {
+	accountId, mailboxId, invalidFields := parseIds(r, "mailbox_id")
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	getMailboxRequest := &models.GetMailboxRequest{
+		AccountID: accountId,
+		MailboxID: mailboxId,
+	}
+
+	isValid, invalidFields := getMailboxRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return getMailboxRequest, nil
+}
+
+func NewListMailboxesRequestFromHttpRequest(r *http.Request) (*models.ListMailboxesRequest, error) {
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"account_id"})
+	}
+
+	listMailboxesRequest := &models.ListMailboxesRequest{
+		AccountID: accountId,
+	}
+
+	isValid, invalidFields := listMailboxesRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return listMailboxesRequest, nil
+}
+
+func NewUpdateMailboxRequestFromHttpRequest(r *http.Request) (*models.UpdateMailboxRequest, error) {
+	updateMailboxRequest := &models.UpdateMailboxRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateMailboxRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	mailboxId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"mailbox_id"})
+	}
+	updateMailboxRequest.MailboxID = mailboxId
+
+	isValid, invalidFields := updateMailboxRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return updateMailboxRequest, nil
+}
+
+func NewDeleteMailboxRequestFromHttpRequest(r *http.Request) (*models.DeleteMailboxRequest, error) {
+	accountId, mailboxId, invalidFields := parseIds(r, "mailbox_id")
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	deleteMailboxRequest := &models.DeleteMailboxRequest{
+		AccountID: accountId,
+		MailboxID: mailboxId,
+	}
+
+	isValid, invalidFields := deleteMailboxRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return deleteMailboxRequest, nil
+}
+
+func NewGetSequenceMailboxesRequestFromHttpRequest(r *http.Request) (*models.GetSequenceMailboxesRequest, error) {
+	accountId, sequenceId, invalidFields := parseIds(r, "sequence_id")
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	getSequenceMailboxesRequest := &models.GetSequenceMailboxesRequest{
+		AccountID:  accountId,
+		SequenceID: sequenceId,
+	}
+
+	isValid, invalidFields := getSequenceMailboxesRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return getSequenceMailboxesRequest, nil
+}
+
+func NewSetSequenceMailboxesRequestFromHttpRequest(r *http.Request) (*models.SetSequenceMailboxesRequest, error) {
+	setSequenceMailboxesRequest := &models.SetSequenceMailboxesRequest{}
+	err := json.NewDecoder(r.Body).Decode(setSequenceMailboxesRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	sequenceId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"sequence_id"})
+	}
+	setSequenceMailboxesRequest.SequenceID = sequenceId
+
+	isValid, invalidFields := setSequenceMailboxesRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return setSequenceMailboxesRequest, nil
+}
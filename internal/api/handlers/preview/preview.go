@@ -0,0 +1,54 @@
+package preview
+
+import (
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+)
+
+type PreviewHandler struct {
+	previewService service.PreviewService
+	logger         *zap.Logger
+}
+
+func NewPreviewHandler(previewService service.PreviewService, logger *zap.Logger) *PreviewHandler {
+	return &PreviewHandler{
+		previewService: previewService,
+		logger:         logger,
+	}
+}
+
+func (ph *PreviewHandler) PreviewStep(w http.ResponseWriter, r *http.Request) {
+	ph.logger.Info("PreviewStep request received")
+	previewStepRequest, err := NewPreviewStepRequestFromHttpRequest(r)
+	if err != nil {
+		ph.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	rendered, sendAt, err := ph.previewService.PreviewStep(r.Context(), previewStepRequest)
+	if err != nil {
+		ph.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.PreviewStepResponse{
+		StepID:           previewStepRequest.StepID,
+		VariantID:        rendered.VariantID,
+		Subject:          rendered.Subject,
+		HTMLBody:         rendered.HTMLBody,
+		TextBody:         rendered.TextBody,
+		MissingVariables: rendered.MissingVariables,
+		SendAt:           sendAt,
+		Status:           "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
@@ -0,0 +1,44 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"strconv"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+func NewPreviewStepRequestFromHttpRequest(r *http.Request) (*models.PreviewStepRequest, error) {
+	previewStepRequest := &models.PreviewStepRequest{}
+	err := json.NewDecoder(r.Body).Decode(previewStepRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	stepId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"step_id"})
+	}
+	previewStepRequest.StepID = stepId
+
+	isValid, invalidFields := previewStepRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return previewStepRequest, nil
+}
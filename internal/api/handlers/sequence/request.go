@@ -2,72 +2,346 @@ package sequence
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"net/http"
+	sfErr "salesforge-api/internal/errors"
 	"salesforge-api/internal/models"
+	"salesforge-api/internal/templating"
+	"strconv"
 )
 
 const (
-	RequestDecodeError     = "requestDecodeError"
-	InvalidParametersError = "invalidParametersError"
+	RequestDecodeError = "requestDecodeError"
 )
 
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+type templateField struct {
+	name string
+	src  string
+}
+
+func variantTemplateFields(prefix string, variants []models.StepVariant) []templateField {
+	var fields []templateField
+	for i, variant := range variants {
+		fields = append(fields,
+			templateField{name: fmt.Sprintf("%svariants[%d].step_email_subject", prefix, i), src: variant.StepEmailSubject},
+			templateField{name: fmt.Sprintf("%svariants[%d].step_email_body", prefix, i), src: variant.StepEmailBody},
+		)
+	}
+	return fields
+}
+
+// validateTemplates rejects step subjects and bodies that don't parse or use
+// unknown variables, giving the reason for each field.
+func validateTemplates(fields []templateField) error {
+	var invalidParams []sfErr.InvalidParam
+	for _, field := range fields {
+		if err := templating.Validate(field.src); err != nil {
+			invalidParams = append(invalidParams, sfErr.InvalidParam{Name: field.name, Reason: err.Error()})
+		}
+	}
+	if len(invalidParams) > 0 {
+		return sfErr.NewInvalidParamReasonsError("invalid step template", invalidParams)
+	}
+	return nil
+}
+
 func NewAddSequenceRequestFromHttpRequest(r *http.Request) (*models.AddSequenceRequest, error) {
 	addSequenceRequest := &models.AddSequenceRequest{}
 	err := json.NewDecoder(r.Body).Decode(addSequenceRequest)
 	if err != nil {
-		return nil, errors.New(RequestDecodeError)
+		return nil, newDecodeError(err)
 	}
 
 	isValid, invalidFields := addSequenceRequest.Validate()
 	if !isValid {
-		return nil, fmt.Errorf("%s: %v", InvalidParametersError, invalidFields)
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	var fields []templateField
+	for i, step := range addSequenceRequest.Steps {
+		fields = append(fields,
+			templateField{name: fmt.Sprintf("steps[%d].step_email_subject", i), src: step.StepEmailSubject},
+			templateField{name: fmt.Sprintf("steps[%d].step_email_body", i), src: step.StepEmailBody},
+		)
+		fields = append(fields, variantTemplateFields(fmt.Sprintf("steps[%d].", i), step.Variants)...)
+	}
+	if err := validateTemplates(fields); err != nil {
+		return nil, err
 	}
 
 	return addSequenceRequest, nil
 }
 
+func NewCloneSequenceRequestFromHttpRequest(r *http.Request) (*models.CloneSequenceRequest, error) {
+	cloneSequenceRequest := &models.CloneSequenceRequest{}
+	err := json.NewDecoder(r.Body).Decode(cloneSequenceRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	sequenceId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"sequence_id"})
+	}
+	cloneSequenceRequest.SequenceID = sequenceId
+
+	isValid, invalidFields := cloneSequenceRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return cloneSequenceRequest, nil
+}
+
+func NewGetSequenceRequestFromHttpRequest(r *http.Request) (*models.GetSequenceRequest, error) {
+	getSequenceRequest := &models.GetSequenceRequest{}
+	var invalidFields []string
+
+	sequenceId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "sequence_id")
+	}
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	getSequenceRequest.SequenceID = sequenceId
+	getSequenceRequest.AccountID = accountId
+
+	isValid, invalidFields := getSequenceRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return getSequenceRequest, nil
+}
+
+func NewGetVariantStatsRequestFromHttpRequest(r *http.Request) (*models.GetVariantStatsRequest, error) {
+	getVariantStatsRequest := &models.GetVariantStatsRequest{}
+	var invalidFields []string
+
+	stepId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "step_id")
+	}
+	accountId, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		invalidFields = append(invalidFields, "account_id")
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	getVariantStatsRequest.StepID = stepId
+	getVariantStatsRequest.AccountID = accountId
+
+	isValid, invalidFields := getVariantStatsRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return getVariantStatsRequest, nil
+}
+
+func NewListSequencesRequestFromHttpRequest(r *http.Request) (*models.ListSequencesRequest, error) {
+	query := r.URL.Query()
+	listSequencesRequest := &models.ListSequencesRequest{
+		Name:      query.Get("name"),
+		SortBy:    models.SequenceSortByCreatedAt,
+		SortOrder: models.SortOrderDesc,
+		Limit:     models.DefaultListLimit,
+	}
+	var invalidFields []string
+
+	parseInt := func(name string, dst *int64) {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				invalidFields = append(invalidFields, name)
+				return
+			}
+			*dst = n
+		}
+	}
+	parseBool := func(name string, dst **bool) {
+		if v := query.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				invalidFields = append(invalidFields, name)
+				return
+			}
+			*dst = &b
+		}
+	}
+
+	parseInt("account_id", &listSequencesRequest.AccountID)
+	parseInt("created_from", &listSequencesRequest.CreatedFrom)
+	parseInt("created_to", &listSequencesRequest.CreatedTo)
+	parseInt("updated_from", &listSequencesRequest.UpdatedFrom)
+	parseInt("updated_to", &listSequencesRequest.UpdatedTo)
+	parseBool("open_tracking_enabled", &listSequencesRequest.OpenTrackingEnabled)
+	parseBool("click_tracking_enabled", &listSequencesRequest.ClickTrackingEnabled)
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			invalidFields = append(invalidFields, "limit")
+		}
+		listSequencesRequest.Limit = limit
+	}
+	if v := query.Get("sort_by"); v != "" {
+		listSequencesRequest.SortBy = v
+	}
+	if v := query.Get("sort_order"); v != "" {
+		listSequencesRequest.SortOrder = v
+	}
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := models.DecodeSequenceCursor(v)
+		if err != nil {
+			invalidFields = append(invalidFields, "cursor")
+		}
+		listSequencesRequest.Cursor = cursor
+	}
+	if len(invalidFields) > 0 {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	isValid, invalidFields := listSequencesRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return listSequencesRequest, nil
+}
+
 func NewUpdateSequenceRequestFromHttpRequest(r *http.Request) (*models.UpdateSequenceRequest, error) {
 	updateSequenceRequest := &models.UpdateSequenceRequest{}
 	err := json.NewDecoder(r.Body).Decode(updateSequenceRequest)
 	if err != nil {
-		return nil, errors.New(RequestDecodeError)
+		return nil, newDecodeError(err)
 	}
 
 	isValid, invalidFields := updateSequenceRequest.Validate()
 	if !isValid {
-		return nil, fmt.Errorf("%s: %v", InvalidParametersError, invalidFields)
+		return nil, newInvalidParametersError(invalidFields)
 	}
 
 	return updateSequenceRequest, nil
 }
 
+func NewDeleteSequenceRequestFromHttpRequest(r *http.Request) (*models.DeleteSequenceRequest, error) {
+	deleteSequenceRequest := &models.DeleteSequenceRequest{}
+	err := json.NewDecoder(r.Body).Decode(deleteSequenceRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	isValid, invalidFields := deleteSequenceRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return deleteSequenceRequest, nil
+}
+
+func NewAddStepRequestFromHttpRequest(r *http.Request) (*models.AddStepRequest, error) {
+	addStepRequest := &models.AddStepRequest{}
+	err := json.NewDecoder(r.Body).Decode(addStepRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	isValid, invalidFields := addStepRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	fields := []templateField{
+		{name: "step_email_subject", src: addStepRequest.StepEmailSubject},
+		{name: "step_email_body", src: addStepRequest.StepEmailBody},
+	}
+	fields = append(fields, variantTemplateFields("", addStepRequest.Variants)...)
+	err = validateTemplates(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return addStepRequest, nil
+}
+
 func NewUpdateStepRequestFromHttpRequest(r *http.Request) (*models.UpdateStepRequest, error) {
 	updateStepRequest := &models.UpdateStepRequest{}
 	err := json.NewDecoder(r.Body).Decode(updateStepRequest)
 	if err != nil {
-		return nil, errors.New(RequestDecodeError)
+		return nil, newDecodeError(err)
 	}
 
 	isValid, invalidFields := updateStepRequest.Validate()
 	if !isValid {
-		return nil, fmt.Errorf("%s: %v", InvalidParametersError, invalidFields)
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	var fields []templateField
+	if updateStepRequest.StepEmailSubject != nil {
+		fields = append(fields, templateField{name: "step_email_subject", src: *updateStepRequest.StepEmailSubject})
+	}
+	if updateStepRequest.StepEmailBody != nil {
+		fields = append(fields, templateField{name: "step_email_body", src: *updateStepRequest.StepEmailBody})
+	}
+	if updateStepRequest.Variants != nil {
+		fields = append(fields, variantTemplateFields("", *updateStepRequest.Variants)...)
+	}
+	if err := validateTemplates(fields); err != nil {
+		return nil, err
 	}
 
 	return updateStepRequest, nil
 }
 
+func NewReorderStepsRequestFromHttpRequest(r *http.Request) (*models.ReorderStepsRequest, error) {
+	reorderStepsRequest := &models.ReorderStepsRequest{}
+	err := json.NewDecoder(r.Body).Decode(reorderStepsRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	sequenceId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, newInvalidParametersError([]string{"sequence_id"})
+	}
+	reorderStepsRequest.SequenceID = sequenceId
+
+	isValid, invalidFields := reorderStepsRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return reorderStepsRequest, nil
+}
+
 func NewDeleteStepRequestFromHttpRequest(r *http.Request) (*models.DeleteStepRequest, error) {
 	deleteStepRequest := &models.DeleteStepRequest{}
 	err := json.NewDecoder(r.Body).Decode(deleteStepRequest)
 	if err != nil {
-		return nil, errors.New(RequestDecodeError)
+		return nil, newDecodeError(err)
 	}
 
 	isValid, invalidFields := deleteStepRequest.Validate()
 	if !isValid {
-		return nil, fmt.Errorf("%s: %v", InvalidParametersError, invalidFields)
+		return nil, newInvalidParametersError(invalidFields)
 	}
 
 	return deleteStepRequest, nil
@@ -25,9 +25,8 @@ func (sh *SequenceHandler) AddSequence(w http.ResponseWriter, r *http.Request) {
 	sh.logger.Info("AddSequence request received")
 	addSequenceRequest, err := NewAddSequenceRequestFromHttpRequest(r)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusBadRequest, "invalid request payload", err)
-		sh.logger.Error("error decoding request", zap.Error(appErr))
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
@@ -35,9 +34,8 @@ func (sh *SequenceHandler) AddSequence(w http.ResponseWriter, r *http.Request) {
 	steps := addSequenceRequest.Steps
 	sequenceId, err := sh.sequenceService.AddSequence(r.Context(), &sequence, &steps)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusInternalServerError, "failed to add sequence", err)
-		sh.logger.Error("error processing request", zap.Error(appErr))
-		http.Error(w, "An error occurred", http.StatusInternalServerError)
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
@@ -51,21 +49,131 @@ func (sh *SequenceHandler) AddSequence(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (sh *SequenceHandler) CloneSequence(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("CloneSequence request received")
+	cloneSequenceRequest, err := NewCloneSequenceRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	accountId, sequenceId, err := sh.sequenceService.CloneSequence(r.Context(), cloneSequenceRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.CloneSequenceResponse{
+		AccountID:  accountId,
+		SequenceID: sequenceId,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (sh *SequenceHandler) GetSequence(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("GetSequence request received")
+	getSequenceRequest, err := NewGetSequenceRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequence, steps, err := sh.sequenceService.GetSequence(r.Context(), getSequenceRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.GetSequenceResponse{
+		Sequence: *sequence,
+		Steps:    steps,
+		Status:   "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (sh *SequenceHandler) GetVariantStats(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("GetVariantStats request received")
+	getVariantStatsRequest, err := NewGetVariantStatsRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	step, stats, err := sh.sequenceService.GetVariantStats(r.Context(), getVariantStatsRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.GetVariantStatsResponse{
+		StepID:            step.StepID,
+		AutoPromoteAfter:  step.AutoPromoteAfter,
+		PromotedVariantID: step.PromotedVariantID,
+		Variants:          stats,
+		Status:            "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (sh *SequenceHandler) ListSequences(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("ListSequences request received")
+	listSequencesRequest, err := NewListSequencesRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequences, next, err := sh.sequenceService.ListSequences(r.Context(), listSequencesRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.ListSequencesResponse{
+		Sequences: sequences,
+		Status:    "ok",
+	}
+	if next != nil {
+		res.NextCursor = next.Encode()
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
 func (sh *SequenceHandler) UpdateSequence(w http.ResponseWriter, r *http.Request) {
 	sh.logger.Info("UpdateSequence request received")
 	updateSequenceRequest, err := NewUpdateSequenceRequestFromHttpRequest(r)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusBadRequest, "invalid request payload", err)
-		sh.logger.Error("error decoding request", zap.Error(appErr))
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
 	sequenceId, err := sh.sequenceService.UpdateSequence(r.Context(), updateSequenceRequest)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusInternalServerError, "failed to update sequence", err)
-		sh.logger.Error("error processing request", zap.Error(appErr))
-		http.Error(w, "An error occurred", http.StatusInternalServerError)
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
@@ -79,21 +187,73 @@ func (sh *SequenceHandler) UpdateSequence(w http.ResponseWriter, r *http.Request
 	return
 }
 
+func (sh *SequenceHandler) DeleteSequence(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("DeleteSequence request received")
+	deleteSequenceRequest, err := NewDeleteSequenceRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequenceId, err := sh.sequenceService.DeleteSequence(r.Context(), deleteSequenceRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.DeleteSequenceResponse{
+		SequenceID: sequenceId,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
+func (sh *SequenceHandler) AddStep(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("AddStep request received")
+	addStepRequest, err := NewAddStepRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequenceId, stepId, stepOrder, err := sh.sequenceService.AddStep(r.Context(), addStepRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddStepResponse{
+		SequenceID: sequenceId,
+		StepID:     stepId,
+		StepOrder:  stepOrder,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
 func (sh *SequenceHandler) UpdateStep(w http.ResponseWriter, r *http.Request) {
 	sh.logger.Info("UpdateStep request received")
 	updateStepRequest, err := NewUpdateStepRequestFromHttpRequest(r)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusBadRequest, "invalid request payload", err)
-		sh.logger.Error("error decoding request", zap.Error(appErr))
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
 	sequenceId, stepId, err := sh.sequenceService.UpdateStep(r.Context(), updateStepRequest)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusInternalServerError, "failed to update step", err)
-		sh.logger.Error("error processing request", zap.Error(appErr))
-		http.Error(w, "An error occurred", http.StatusInternalServerError)
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
@@ -108,21 +268,46 @@ func (sh *SequenceHandler) UpdateStep(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (sh *SequenceHandler) ReorderSteps(w http.ResponseWriter, r *http.Request) {
+	sh.logger.Info("ReorderSteps request received")
+	reorderStepsRequest, err := NewReorderStepsRequestFromHttpRequest(r)
+	if err != nil {
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	sequenceId, err := sh.sequenceService.ReorderSteps(r.Context(), reorderStepsRequest)
+	if err != nil {
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.ReorderStepsResponse{
+		SequenceID: sequenceId,
+		StepIDs:    reorderStepsRequest.StepIDs,
+		Status:     "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
+
 func (sh *SequenceHandler) DeleteStep(w http.ResponseWriter, r *http.Request) {
 	sh.logger.Info("DeleteStep request received")
 	deleteStepRequest, err := NewDeleteStepRequestFromHttpRequest(r)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusBadRequest, "invalid request payload", err)
-		sh.logger.Error("error decoding request", zap.Error(appErr))
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		sh.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
 	sequenceId, stepId, err := sh.sequenceService.DeleteStep(r.Context(), deleteStepRequest)
 	if err != nil {
-		appErr := errors.NewAppError(http.StatusInternalServerError, "failed to delete step", err)
-		sh.logger.Error("error processing request", zap.Error(appErr))
-		http.Error(w, "an error occurred", http.StatusInternalServerError)
+		sh.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
 		return
 	}
 
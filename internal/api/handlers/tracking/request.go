@@ -0,0 +1,36 @@
+package tracking
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+)
+
+const (
+	RequestDecodeError = "requestDecodeError"
+)
+
+func newDecodeError(err error) error {
+	return sfErr.NewAppError(http.StatusBadRequest, "request body is not valid JSON", fmt.Errorf("%s: %w", RequestDecodeError, err))
+}
+
+func newInvalidParametersError(invalidFields []string) error {
+	return sfErr.NewInvalidParamsError("invalid request parameters", invalidFields)
+}
+
+func NewAddReplyRequestFromHttpRequest(r *http.Request) (*models.AddReplyRequest, error) {
+	addReplyRequest := &models.AddReplyRequest{}
+	err := json.NewDecoder(r.Body).Decode(addReplyRequest)
+	if err != nil {
+		return nil, newDecodeError(err)
+	}
+
+	isValid, invalidFields := addReplyRequest.Validate()
+	if !isValid {
+		return nil, newInvalidParametersError(invalidFields)
+	}
+
+	return addReplyRequest, nil
+}
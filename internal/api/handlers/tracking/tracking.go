@@ -0,0 +1,129 @@
+package tracking
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/service"
+	"salesforge-api/internal/tracking"
+	"strings"
+)
+
+// TrackingHandler serves the public tracking URLs embedded in sent emails.
+// X-Forwarded-For is only believed from trustedProxies.
+type TrackingHandler struct {
+	trackingService service.TrackingService
+	trustedProxies  []*net.IPNet
+	logger          *zap.Logger
+}
+
+func NewTrackingHandler(trackingService service.TrackingService, trustedProxies []*net.IPNet, logger *zap.Logger) *TrackingHandler {
+	return &TrackingHandler{
+		trackingService: trackingService,
+		trustedProxies:  trustedProxies,
+		logger:          logger,
+	}
+}
+
+func (th *TrackingHandler) trusted(ip net.IP) bool {
+	for _, proxy := range th.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP is the remote address, unless that is a trusted proxy: then
+// X-Forwarded-For is followed from the right, past the trusted proxies, to
+// the address that connected to the first of them. Anything that isn't an
+// address is ignored, so the result is always a valid IP or empty.
+func (th *TrackingHandler) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && th.trusted(ip); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
+
+// Open always serves the pixel so that mail clients never show a broken
+// image; failures to record the open are only logged.
+func (th *TrackingHandler) Open(w http.ResponseWriter, r *http.Request) {
+	th.logger.Debug("Open request received")
+	_, err := th.trackingService.TrackOpen(r.Context(), chi.URLParam(r, "token"), r.UserAgent(), th.clientIP(r))
+	if err != nil {
+		th.logger.Warn("error recording open", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(http.StatusOK)
+	w.Write(tracking.Pixel)
+}
+
+// Click redirects to the link the token was signed for. Tokens that don't
+// verify get a 404 rather than a redirect, so the endpoint can't be used as
+// an open redirect.
+func (th *TrackingHandler) Click(w http.ResponseWriter, r *http.Request) {
+	th.logger.Debug("Click request received")
+	url, err := th.trackingService.TrackClick(r.Context(), chi.URLParam(r, "token"), r.UserAgent(), th.clientIP(r))
+	if url == "" {
+		th.logger.Warn("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	if err != nil {
+		th.logger.Warn("error recording click", zap.Error(err))
+	}
+
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// AddReply records a reply to a sent step. Unlike the tracking URLs it is
+// called by the account, and authenticated.
+func (th *TrackingHandler) AddReply(w http.ResponseWriter, r *http.Request) {
+	th.logger.Info("AddReply request received")
+	addReplyRequest, err := NewAddReplyRequestFromHttpRequest(r)
+	if err != nil {
+		th.logger.Error("error decoding request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	eventId, err := th.trackingService.RecordReply(r.Context(), addReplyRequest)
+	if err != nil {
+		th.logger.Error("error processing request", zap.Error(err))
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	res := models.AddReplyResponse{
+		EventID: eventId,
+		Status:  "ok",
+	}
+
+	render.Status(r, 200)
+	render.JSON(w, r, res)
+	return
+}
@@ -6,8 +6,15 @@ import (
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
+	"salesforge-api/internal/api/handlers/apikey"
+	"salesforge-api/internal/api/handlers/contact"
+	"salesforge-api/internal/api/handlers/enrollment"
 	"salesforge-api/internal/api/handlers/healthcheck"
+	"salesforge-api/internal/api/handlers/mailbox"
+	"salesforge-api/internal/api/handlers/preview"
 	"salesforge-api/internal/api/handlers/sequence"
+	"salesforge-api/internal/api/handlers/tracking"
+	"salesforge-api/internal/auth"
 	"salesforge-api/internal/config"
 	"salesforge-api/internal/middleware"
 	"salesforge-api/internal/monitoring"
@@ -17,20 +24,41 @@ import (
 
 func NewServer(
 	conf config.ServerConfig,
+	verifier *middleware.JWTVerifier,
 	sequenceService service.SequenceService,
+	contactService service.ContactService,
+	enrollmentService service.EnrollmentService,
+	mailboxService service.MailboxService,
+	previewService service.PreviewService,
+	apiKeyService service.APIKeyService,
+	trackingService service.TrackingService,
 	l *zap.Logger,
 ) *http.Server {
 	r := chi.NewRouter()
 	r.Use(middleware.LoggingMiddleware(l))
 	r.Use(middleware.ErrorHandlingMiddleware(l))
 
-	if conf.JWTAuthentication {
-		r.Use(middleware.Authenticate)
-	}
+	// TrustedProxies was checked when the config was loaded
+	trustedProxies, _ := conf.TrustedProxyNets()
+	trackingHandler := tracking.NewTrackingHandler(trackingService, trustedProxies, l)
+
+	// Tracking URLs are opened by mail clients and never authenticated.
+	trackingHandlers(r, trackingHandler)
+
+	r.Group(func(r chi.Router) {
+		if conf.JWTAuthentication || conf.APIKeyAuthentication {
+			var apiKeys middleware.APIKeyAuthenticator
+			if conf.APIKeyAuthentication {
+				apiKeys = apiKeyService
+			}
+			r.Use(middleware.Authenticate(verifier, apiKeys, l))
+		}
+		handlers(r, sequenceService, contactService, enrollmentService, mailboxService, previewService, apiKeyService, trackingHandler, l)
+	})
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", conf.AppServerPort),
-		Handler: handlers(r, sequenceService, l),
+		Handler: r,
 	}
 
 	return server
@@ -54,41 +82,242 @@ func NewHealthCheckServer(
 	return server
 }
 
+func trackingHandlers(
+	r chi.Router,
+	trackingHandler *tracking.TrackingHandler,
+) {
+	r.Get("/t/o/{token}", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		trackingHandler.Open(w, r)
+		duration := time.Since(start).Seconds()
+		monitoring.RecordMetrics("/t/o/{token}", duration)
+	})
+	r.Get("/t/c/{token}", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		trackingHandler.Click(w, r)
+		duration := time.Since(start).Seconds()
+		monitoring.RecordMetrics("/t/c/{token}", duration)
+	})
+}
+
 func handlers(
-	r *chi.Mux,
+	r chi.Router,
 	sequenceService service.SequenceService,
+	contactService service.ContactService,
+	enrollmentService service.EnrollmentService,
+	mailboxService service.MailboxService,
+	previewService service.PreviewService,
+	apiKeyService service.APIKeyService,
+	trackingHandler *tracking.TrackingHandler,
 	l *zap.Logger,
-) *chi.Mux {
+) {
 	sequenceHandler := sequence.NewSequenceHandler(sequenceService, l)
+	contactHandler := contact.NewContactHandler(contactService, l)
+	enrollmentHandler := enrollment.NewEnrollmentHandler(enrollmentService, l)
+	mailboxHandler := mailbox.NewMailboxHandler(mailboxService, l)
+	previewHandler := preview.NewPreviewHandler(previewService, l)
+	apiKeyHandler := apikey.NewAPIKeyHandler(apiKeyService, l)
+
+	// Viewers may only read; editors and API keys may also write; only
+	// owners may manage API keys.
+	read := middleware.RequirePermission(auth.PermissionRead, l)
+	write := middleware.RequirePermission(auth.PermissionWrite, l)
+	manageAPIKeys := middleware.RequirePermission(auth.PermissionManageAPIKeys, l)
 
 	r.Route("/v1", func(r chi.Router) {
-		r.Post("/sequence", func(w http.ResponseWriter, r *http.Request) {
+		r.With(write).Post("/sequence", func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			sequenceHandler.AddSequence(w, r)
 			duration := time.Since(start).Seconds()
 			monitoring.RecordMetrics("/v1/sequence", duration)
 		})
-		r.Put("/sequence", func(w http.ResponseWriter, r *http.Request) {
+		r.With(read).Get("/sequences", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.ListSequences(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequences", duration)
+		})
+		r.With(read).Get("/sequences/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.GetSequence(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequences/{id}", duration)
+		})
+		r.With(write).Put("/sequence", func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			sequenceHandler.UpdateSequence(w, r)
 			duration := time.Since(start).Seconds()
 			monitoring.RecordMetrics("/v1/sequence", duration)
 		})
-		r.Put("/step", func(w http.ResponseWriter, r *http.Request) {
+		r.With(write).Delete("/sequence", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.DeleteSequence(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence", duration)
+		})
+		r.With(write).Post("/sequence/{id}/clone", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.CloneSequence(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence/{id}/clone", duration)
+		})
+		r.With(write).Put("/sequence/{id}/steps/order", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.ReorderSteps(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence/{id}/steps/order", duration)
+		})
+		r.With(write).Post("/step", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.AddStep(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/step", duration)
+		})
+		r.With(write).Put("/step", func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			sequenceHandler.UpdateStep(w, r)
 			duration := time.Since(start).Seconds()
 			monitoring.RecordMetrics("/v1/step", duration)
 		})
-		r.Delete("/step", func(w http.ResponseWriter, r *http.Request) {
+		r.With(write).Delete("/step", func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			sequenceHandler.DeleteStep(w, r)
 			duration := time.Since(start).Seconds()
 			monitoring.RecordMetrics("/v1/step", duration)
 		})
+		r.With(read).Get("/step/{id}/variants/stats", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sequenceHandler.GetVariantStats(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/step/{id}/variants/stats", duration)
+		})
+		r.With(read).Post("/step/{id}/preview", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			previewHandler.PreviewStep(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/step/{id}/preview", duration)
+		})
+		r.With(write).Post("/contacts", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			contactHandler.AddContact(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/contacts", duration)
+		})
+		r.With(read).Get("/contacts", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			contactHandler.ListContacts(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/contacts", duration)
+		})
+		r.With(read).Get("/contacts/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			contactHandler.GetContact(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/contacts/{id}", duration)
+		})
+		r.With(write).Put("/contacts/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			contactHandler.UpdateContact(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/contacts/{id}", duration)
+		})
+		r.With(write).Delete("/contacts/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			contactHandler.DeleteContact(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/contacts/{id}", duration)
+		})
+		r.With(write).Post("/sequence/{id}/enrollments", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			enrollmentHandler.AddEnrollments(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence/{id}/enrollments", duration)
+		})
+		r.With(write).Put("/enrollments/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			enrollmentHandler.PauseEnrollment(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/enrollments/{id}/pause", duration)
+		})
+		r.With(write).Put("/enrollments/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			enrollmentHandler.ResumeEnrollment(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/enrollments/{id}/resume", duration)
+		})
+		r.With(write).Delete("/enrollments/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			enrollmentHandler.DeleteEnrollment(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/enrollments/{id}", duration)
+		})
+		r.With(write).Post("/mailboxes", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.AddMailbox(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/mailboxes", duration)
+		})
+		r.With(read).Get("/mailboxes", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.ListMailboxes(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/mailboxes", duration)
+		})
+		r.With(read).Get("/mailboxes/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.GetMailbox(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/mailboxes/{id}", duration)
+		})
+		r.With(write).Put("/mailboxes/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.UpdateMailbox(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/mailboxes/{id}", duration)
+		})
+		r.With(write).Delete("/mailboxes/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.DeleteMailbox(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/mailboxes/{id}", duration)
+		})
+		r.With(read).Get("/sequence/{id}/mailboxes", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.GetSequenceMailboxes(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence/{id}/mailboxes", duration)
+		})
+		r.With(write).Put("/sequence/{id}/mailboxes", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mailboxHandler.SetSequenceMailboxes(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/sequence/{id}/mailboxes", duration)
+		})
+		r.With(write).Post("/replies", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			trackingHandler.AddReply(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/replies", duration)
+		})
+		r.With(manageAPIKeys).Post("/api-keys", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			apiKeyHandler.AddAPIKey(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/api-keys", duration)
+		})
+		r.With(manageAPIKeys).Get("/api-keys", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			apiKeyHandler.ListAPIKeys(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/api-keys", duration)
+		})
+		r.With(manageAPIKeys).Delete("/api-keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			apiKeyHandler.RevokeAPIKey(w, r)
+			duration := time.Since(start).Seconds()
+			monitoring.RecordMetrics("/v1/api-keys/{id}", duration)
+		})
 	})
 
 	r.Get("/metrics", http.HandlerFunc(monitoring.MetricsHandler().ServeHTTP))
-
-	return r
 }
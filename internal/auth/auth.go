@@ -0,0 +1,59 @@
+// Package auth carries who is making a request, as established by the
+// authentication middleware, from the handlers down to the services.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrForbidden is returned for requests outside the caller's account.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUnauthenticated is returned for credentials that don't identify a
+// caller.
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrInvalidAPIKey   = fmt.Errorf("%w: invalid api key", ErrUnauthenticated)
+	ErrRevokedAPIKey   = fmt.Errorf("%w: api key revoked", ErrUnauthenticated)
+)
+
+// Identity is the authenticated caller. Every request is scoped to its
+// AccountID. Callers using an API key have its APIKeyID and are limited to
+// its Scopes.
+type Identity struct {
+	AccountID int64
+	UserID    string
+	Roles     []string
+	APIKeyID  int64
+	Scopes    []string
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the caller, or false when the request was not
+// authenticated, because authentication is off or the call is internal.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(*Identity)
+	return identity, ok
+}
+
+// CheckAccount fails with ErrForbidden unless every account ID is the
+// caller's. Unauthenticated contexts are not restricted.
+func CheckAccount(ctx context.Context, accountIds ...int64) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, accountId := range accountIds {
+		if accountId != identity.AccountID {
+			return fmt.Errorf("%w: account %d is not the caller's account %d", ErrForbidden, accountId, identity.AccountID)
+		}
+	}
+	return nil
+}
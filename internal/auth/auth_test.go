@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckAccount(t *testing.T) {
+	assert.NoError(t, CheckAccount(context.Background(), 1, 2))
+
+	ctx := NewContext(context.Background(), &Identity{AccountID: 1})
+	assert.NoError(t, CheckAccount(ctx, 1))
+	assert.NoError(t, CheckAccount(ctx, 1, 1))
+
+	err := CheckAccount(ctx, 1, 2)
+	assert.True(t, errors.Is(err, ErrForbidden))
+}
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity Identity
+		want     map[Permission]bool
+	}{
+		{
+			name:     "owner",
+			identity: Identity{Roles: []string{RoleOwner}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: true, PermissionManageAPIKeys: true},
+		},
+		{
+			name:     "editor",
+			identity: Identity{Roles: []string{RoleEditor}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: true, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "viewer",
+			identity: Identity{Roles: []string{RoleViewer}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: false, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "viewer and editor",
+			identity: Identity{Roles: []string{RoleViewer, RoleEditor}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: true, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "no roles",
+			identity: Identity{Roles: []string{"admin"}},
+			want:     map[Permission]bool{PermissionRead: false, PermissionWrite: false, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "read key",
+			identity: Identity{Roles: []string{RoleAPI}, APIKeyID: 1, Scopes: []string{"read"}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: false, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "write key",
+			identity: Identity{Roles: []string{RoleAPI}, APIKeyID: 1, Scopes: []string{"write"}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: true, PermissionManageAPIKeys: false},
+		},
+		{
+			name:     "key claiming owner",
+			identity: Identity{Roles: []string{RoleOwner}, APIKeyID: 1, Scopes: []string{"write"}},
+			want:     map[Permission]bool{PermissionRead: true, PermissionWrite: true, PermissionManageAPIKeys: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for permission, want := range tt.want {
+				assert.Equal(t, want, tt.identity.Can(permission), permission)
+			}
+		})
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	assert.NoError(t, CheckPermission(context.Background(), PermissionWrite))
+
+	ctx := NewContext(context.Background(), &Identity{AccountID: 1, Roles: []string{RoleViewer}})
+	assert.NoError(t, CheckPermission(ctx, PermissionRead))
+	assert.ErrorIs(t, CheckPermission(ctx, PermissionWrite), ErrForbidden)
+}
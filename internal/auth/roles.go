@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Roles a caller may have. Tokens carry theirs in the roles claim; API keys
+// always act with RoleAPI, limited by their scopes.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+	RoleAPI    = "api"
+)
+
+// Permission is what a route requires of the caller. Read and write match
+// the API key scopes of the same name.
+type Permission string
+
+const (
+	PermissionRead          Permission = "read"
+	PermissionWrite         Permission = "write"
+	PermissionManageAPIKeys Permission = "manage_api_keys"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner:  {PermissionRead, PermissionWrite, PermissionManageAPIKeys},
+	RoleEditor: {PermissionRead, PermissionWrite},
+	RoleViewer: {PermissionRead},
+	RoleAPI:    {PermissionRead, PermissionWrite},
+}
+
+// scopePermissions is what each API key scope allows; a write key may read
+// too.
+var scopePermissions = map[string][]Permission{
+	string(PermissionRead):  {PermissionRead},
+	string(PermissionWrite): {PermissionRead, PermissionWrite},
+}
+
+func grants(permissions map[string][]Permission, names []string, permission Permission) bool {
+	for _, name := range names {
+		for _, p := range permissions[name] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Can reports whether one of the caller's roles grants permission and, for
+// API keys, one of its scopes allows it. Unknown roles grant nothing.
+func (i *Identity) Can(permission Permission) bool {
+	if !grants(rolePermissions, i.Roles, permission) {
+		return false
+	}
+	if i.APIKeyID != 0 && !grants(scopePermissions, i.Scopes, permission) {
+		return false
+	}
+	return true
+}
+
+// CheckPermission fails with ErrForbidden unless the caller has permission.
+// Unauthenticated contexts are not restricted.
+func CheckPermission(ctx context.Context, permission Permission) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if !identity.Can(permission) {
+		return fmt.Errorf("%w: caller lacks the %s permission", ErrForbidden, permission)
+	}
+	return nil
+}
@@ -4,21 +4,59 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"io/fs"
+	"net"
+	"strings"
 )
 
 type Config struct {
-	ServiceName string       `yaml:"ServiceName"`
-	Environment string       `yaml:"Environment"`
-	Server      ServerConfig `yaml:"Server"`
-	Psql        PsqlConfig   `yaml:"Psql"`
-	TestDB      PsqlConfig   `yaml:"TestDB"`
-	Logger      LoggerConfig `yaml:"Logger"`
+	ServiceName string          `yaml:"ServiceName"`
+	Environment string          `yaml:"Environment"`
+	Server      ServerConfig    `yaml:"Server"`
+	Psql        PsqlConfig      `yaml:"Psql"`
+	TestDB      PsqlConfig      `yaml:"TestDB"`
+	Logger      LoggerConfig    `yaml:"Logger"`
+	Scheduler   SchedulerConfig `yaml:"Scheduler"`
+	Smtp        SmtpConfig      `yaml:"Smtp"`
+	Tracking    TrackingConfig  `yaml:"Tracking"`
+	Secrets     SecretsConfig   `yaml:"Secrets"`
 }
 
+// ServerConfig turns on authentication with bearer tokens, per-account API
+// keys or both. TrustedProxies are the addresses or CIDR ranges of the
+// reverse proxies in front of the service; X-Forwarded-For is only believed
+// when they set it.
 type ServerConfig struct {
-	AppServerPort     int  `yaml:"AppServerPort"`
-	HealthcheckPort   int  `yaml:"HealthcheckPort"`
-	JWTAuthentication bool `yaml:"JWTAuthentication"`
+	AppServerPort        int       `yaml:"AppServerPort"`
+	HealthcheckPort      int       `yaml:"HealthcheckPort"`
+	JWTAuthentication    bool      `yaml:"JWTAuthentication"`
+	JWT                  JWTConfig `yaml:"JWT"`
+	APIKeyAuthentication bool      `yaml:"APIKeyAuthentication"`
+	TrustedProxies       []string  `yaml:"TrustedProxies"`
+}
+
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+)
+
+// JWTConfig sets how bearer tokens are verified. Only the listed Algorithms
+// are accepted. HS256 tokens are checked against the secrets in SecretFile,
+// one per line, and the one in the SecretEnv variable; RS256 and ES256 tokens
+// against the PEM keys in PublicKeyFiles and the keys published at JWKSURL.
+// The JWKS is cached for JWKSCacheSeconds and fetched again early when a
+// token names a key it doesn't have. With Issuer or Audience set, tokens must
+// carry them. ClockSkewSeconds is the leeway given to exp, nbf and iat.
+type JWTConfig struct {
+	Algorithms       []string `yaml:"Algorithms"`
+	SecretFile       string   `yaml:"SecretFile"`
+	SecretEnv        string   `yaml:"SecretEnv"`
+	PublicKeyFiles   []string `yaml:"PublicKeyFiles"`
+	JWKSURL          string   `yaml:"JWKSURL"`
+	JWKSCacheSeconds int      `yaml:"JWKSCacheSeconds"`
+	Issuer           string   `yaml:"Issuer"`
+	Audience         string   `yaml:"Audience"`
+	ClockSkewSeconds int      `yaml:"ClockSkewSeconds"`
 }
 
 type PsqlConfig struct {
@@ -34,6 +72,49 @@ type LoggerConfig struct {
 	Format string `yaml:"Format"`
 }
 
+type SchedulerConfig struct {
+	Enabled           bool `yaml:"Enabled"`
+	IntervalSeconds   int  `yaml:"IntervalSeconds"`
+	BatchSize         int  `yaml:"BatchSize"`
+	MaxAttempts       int  `yaml:"MaxAttempts"`
+	RetryDelaySeconds int  `yaml:"RetryDelaySeconds"`
+}
+
+const (
+	SmtpDriverSmtp = "smtp"
+	SmtpDriverFile = "file"
+)
+
+type SmtpConfig struct {
+	Driver    string `yaml:"Driver"`
+	Host      string `yaml:"Host"`
+	Port      int    `yaml:"Port"`
+	Username  string `yaml:"Username"`
+	Password  string `yaml:"Password"`
+	StartTLS  bool   `yaml:"StartTLS"`
+	FromEmail string `yaml:"FromEmail"`
+	FromName  string `yaml:"FromName"`
+	Dir       string `yaml:"Dir"`
+}
+
+// TrackingConfig sets the public URL tracking links point to and the secret
+// they are signed with. Tracking is off without a BaseURL.
+type TrackingConfig struct {
+	BaseURL string `yaml:"BaseURL"`
+	Secret  string `yaml:"Secret"`
+}
+
+// SecretsConfig sets the keys secrets stored in the database, such as the
+// SMTP passwords of mailboxes, are encrypted with: base64 encoded 32 byte
+// keys in the KeyEnv variable and in KeyFile, one per line. The first key
+// encrypts; the others only decrypt, so that keys can be rotated. A key is
+// required when the scheduler is enabled; without one, mailboxes can't be
+// given SMTP passwords.
+type SecretsConfig struct {
+	KeyFile string `yaml:"KeyFile"`
+	KeyEnv  string `yaml:"KeyEnv"`
+}
+
 func LoadFromFilesystem(filesystem fs.FS, path string) (cfg Config, err error) {
 	f, err := filesystem.Open(path)
 	if err != nil {
@@ -72,6 +153,24 @@ func (c Config) Validate() error {
 	if err := c.Logger.Validate(); err != nil {
 		return fmt.Errorf("logger config validation failed: %w", err)
 	}
+	if err := c.Scheduler.Validate(); err != nil {
+		return fmt.Errorf("scheduler config validation failed: %w", err)
+	}
+	if c.Scheduler.Enabled {
+		if err := c.Smtp.Validate(); err != nil {
+			return fmt.Errorf("smtp config validation failed: %w", err)
+		}
+	}
+	if err := c.Tracking.Validate(); err != nil {
+		return fmt.Errorf("tracking config validation failed: %w", err)
+	}
+	// The scheduler decrypts the SMTP passwords of mailboxes; without it a
+	// key is only needed to store them.
+	if c.Scheduler.Enabled || c.Secrets.KeyEnv != "" || c.Secrets.KeyFile != "" {
+		if err := c.Secrets.Validate(); err != nil {
+			return fmt.Errorf("secrets config validation failed: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -82,6 +181,67 @@ func (c ServerConfig) Validate() error {
 	if c.HealthcheckPort == 0 {
 		return fmt.Errorf("healthcheck port is required")
 	}
+	if c.JWTAuthentication {
+		if err := c.JWT.Validate(); err != nil {
+			return fmt.Errorf("jwt config validation failed: %w", err)
+		}
+	}
+	if _, err := c.TrustedProxyNets(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TrustedProxyNets parses TrustedProxies; single addresses become ranges of
+// one.
+func (c ServerConfig) TrustedProxyNets() ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, proxy := range c.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q is not an address or cidr range", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is not an address or cidr range", proxy)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func (c JWTConfig) Validate() error {
+	if len(c.Algorithms) == 0 {
+		return fmt.Errorf("algorithms are required")
+	}
+	for _, alg := range c.Algorithms {
+		switch alg {
+		case JWTAlgorithmHS256:
+			if c.SecretFile == "" && c.SecretEnv == "" {
+				return fmt.Errorf("secret file or secret env is required for %s", alg)
+			}
+		case JWTAlgorithmRS256, JWTAlgorithmES256:
+			if len(c.PublicKeyFiles) == 0 && c.JWKSURL == "" {
+				return fmt.Errorf("public key files or jwks url is required for %s", alg)
+			}
+		default:
+			return fmt.Errorf("algorithm must be %q, %q or %q", JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmES256)
+		}
+	}
+	if c.JWKSCacheSeconds < 0 {
+		return fmt.Errorf("jwks cache seconds must not be negative")
+	}
+	if c.ClockSkewSeconds < 0 {
+		return fmt.Errorf("clock skew seconds must not be negative")
+	}
 	return nil
 }
 
@@ -113,3 +273,58 @@ func (c LoggerConfig) Validate() error {
 	}
 	return nil
 }
+
+func (c SchedulerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.IntervalSeconds <= 0 {
+		return fmt.Errorf("interval seconds is required")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch size is required")
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("max attempts is required")
+	}
+	if c.RetryDelaySeconds <= 0 {
+		return fmt.Errorf("retry delay seconds is required")
+	}
+	return nil
+}
+
+func (c SmtpConfig) Validate() error {
+	if c.FromEmail == "" {
+		return fmt.Errorf("from email is required")
+	}
+	switch c.Driver {
+	case SmtpDriverSmtp:
+		if c.Host == "" {
+			return fmt.Errorf("host is required")
+		}
+		if c.Port == 0 {
+			return fmt.Errorf("port is required")
+		}
+	case SmtpDriverFile:
+		if c.Dir == "" {
+			return fmt.Errorf("dir is required")
+		}
+	default:
+		return fmt.Errorf("driver must be %q or %q", SmtpDriverSmtp, SmtpDriverFile)
+	}
+	return nil
+}
+
+func (c TrackingConfig) Validate() error {
+	if c.BaseURL != "" && c.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+	return nil
+}
+
+func (c SecretsConfig) Validate() error {
+	if c.KeyFile == "" && c.KeyEnv == "" {
+		return fmt.Errorf("key file or key env is required")
+	}
+	return nil
+}
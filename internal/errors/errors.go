@@ -2,12 +2,21 @@ package errors
 
 import (
 	"fmt"
+	"net/http"
 )
 
 type AppError struct {
-	Code    int
-	Message string
-	Err     error
+	Code               int
+	Message            string
+	Err                error
+	InvalidParams      []InvalidParam
+	RequiredPermission string
+}
+
+// InvalidParam names a request field that failed validation.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
 }
 
 func (e *AppError) Error() string {
@@ -28,6 +37,47 @@ func NewAppError(code int, message string, err error) *AppError {
 	}
 }
 
+// NewInvalidParamsError builds a 400 AppError listing the fields rejected by
+// a request's Validate method.
+func NewInvalidParamsError(message string, invalidFields []string) *AppError {
+	invalidParams := make([]InvalidParam, 0, len(invalidFields))
+	for _, field := range invalidFields {
+		invalidParams = append(invalidParams, InvalidParam{Name: field, Reason: "missing or invalid value"})
+	}
+	return &AppError{
+		Code:          http.StatusBadRequest,
+		Message:       message,
+		Err:           fmt.Errorf("invalid parameters: %v", invalidFields),
+		InvalidParams: invalidParams,
+	}
+}
+
+// NewInvalidParamReasonsError builds a 400 AppError for fields rejected with
+// a specific reason each.
+func NewInvalidParamReasonsError(message string, invalidParams []InvalidParam) *AppError {
+	invalidFields := make([]string, 0, len(invalidParams))
+	for _, param := range invalidParams {
+		invalidFields = append(invalidFields, param.Name)
+	}
+	return &AppError{
+		Code:          http.StatusBadRequest,
+		Message:       message,
+		Err:           fmt.Errorf("invalid parameters: %v", invalidFields),
+		InvalidParams: invalidParams,
+	}
+}
+
+// NewPermissionError builds a 403 AppError naming the permission the caller
+// lacks.
+func NewPermissionError(permission string, err error) *AppError {
+	return &AppError{
+		Code:               http.StatusForbidden,
+		Message:            fmt.Sprintf("the %s permission is required", permission),
+		Err:                err,
+		RequiredPermission: permission,
+	}
+}
+
 func (e *AppError) Unwrap() error {
 	return e.Err
 }
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. Permission denials name
+// the missing permission in RequiredPermission.
+type Problem struct {
+	Type               string         `json:"type"`
+	Title              string         `json:"title"`
+	Status             int            `json:"status"`
+	Detail             string         `json:"detail,omitempty"`
+	Instance           string         `json:"instance,omitempty"`
+	InvalidParams      []InvalidParam `json:"invalid_params,omitempty"`
+	RequiredPermission string         `json:"required_permission,omitempty"`
+}
+
+var problemTypes = map[int]string{
+	http.StatusBadRequest:          "/problems/invalid-request",
+	http.StatusUnauthorized:        "/problems/unauthorized",
+	http.StatusForbidden:           "/problems/forbidden",
+	http.StatusNotFound:            "/problems/not-found",
+	http.StatusConflict:            "/problems/conflict",
+	http.StatusInternalServerError: "/problems/internal-error",
+}
+
+// NewProblem describes err for the client. Only AppErrors below 500 expose
+// their message; anything else becomes a generic internal error.
+func NewProblem(r *http.Request, err error) *Problem {
+	code, detail := http.StatusInternalServerError, "An error occurred"
+	var invalidParams []InvalidParam
+	var requiredPermission string
+
+	var appErr *AppError
+	if errors.As(err, &appErr) && appErr.Code < http.StatusInternalServerError {
+		code, detail, invalidParams = appErr.Code, appErr.Message, appErr.InvalidParams
+		requiredPermission = appErr.RequiredPermission
+	}
+
+	problemType, ok := problemTypes[code]
+	if !ok {
+		problemType = "about:blank"
+	}
+
+	return &Problem{
+		Type:               problemType,
+		Title:              http.StatusText(code),
+		Status:             code,
+		Detail:             detail,
+		Instance:           r.URL.Path,
+		InvalidParams:      invalidParams,
+		RequiredPermission: requiredPermission,
+	}
+}
+
+// WriteProblem replies to the request with err as problem+json.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := NewProblem(r, err)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem_InvalidParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/v1/sequence", nil)
+	w := httptest.NewRecorder()
+
+	WriteProblem(w, r, NewInvalidParamsError("invalid request parameters", []string{"account_id", "sequence_name"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "/problems/invalid-request", problem.Type)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "invalid request parameters", problem.Detail)
+	assert.Equal(t, "/v1/sequence", problem.Instance)
+	assert.Len(t, problem.InvalidParams, 2)
+	assert.Equal(t, "account_id", problem.InvalidParams[0].Name)
+	assert.Equal(t, "sequence_name", problem.InvalidParams[1].Name)
+}
+
+func TestWriteProblem_HidesInternalErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPut, "/v1/step", nil)
+
+	for _, err := range []error{
+		errors.New("pq: connection refused"),
+		NewAppError(http.StatusInternalServerError, "failed to update step", errors.New("pq: connection refused")),
+	} {
+		w := httptest.NewRecorder()
+		WriteProblem(w, r, err)
+
+		var problem Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Equal(t, "An error occurred", problem.Detail)
+		assert.Empty(t, problem.InvalidParams)
+	}
+}
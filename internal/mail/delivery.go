@@ -0,0 +1,156 @@
+package mail
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"salesforge-api/internal/config"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/templating"
+	"salesforge-api/internal/tracking"
+	"strings"
+	"time"
+)
+
+var htmlTag = regexp.MustCompile(`<[a-zA-Z][^>]*>`)
+
+// Delivery renders due steps and sends them through a Sender. It records
+// the Message-ID of every message on the send. Sends from an account's
+// mailbox go through that mailbox's SMTP server, unless the file driver is
+// configured; the rest use the configured sender and address.
+type Delivery struct {
+	conf          config.SmtpConfig
+	sender        Sender
+	mailboxSender func(mailbox *models.Mailbox) Sender
+	tracker       *tracking.Tracker
+	now           func() time.Time
+}
+
+func NewDelivery(conf config.SmtpConfig, sender Sender, tracker *tracking.Tracker) *Delivery {
+	d := &Delivery{
+		conf:    conf,
+		sender:  sender,
+		tracker: tracker,
+		now:     time.Now,
+	}
+	d.mailboxSender = d.newMailboxSender
+	return d
+}
+
+func (d *Delivery) newMailboxSender(mailbox *models.Mailbox) Sender {
+	if d.conf.Driver == config.SmtpDriverFile {
+		return d.sender
+	}
+	return NewSMTPSender(config.SmtpConfig{
+		Driver:   config.SmtpDriverSmtp,
+		Host:     mailbox.SmtpHost,
+		Port:     mailbox.SmtpPort,
+		Username: mailbox.SmtpUsername,
+		Password: mailbox.SmtpPassword,
+		StartTLS: d.conf.StartTLS,
+	})
+}
+
+// Deliver sends the current step of due, in the variant recorded on the send
+// by an earlier attempt, or else the one the contact gets, and records that
+// variant on the send.
+func (d *Delivery) Deliver(ctx context.Context, due *models.DueSend) error {
+	variant := due.Step.Variant(due.Send.VariantID)
+	if variant == nil {
+		variant = due.Step.PickVariant(due.Contact.Email)
+	}
+	due.Send.VariantID = 0
+	if variant != nil {
+		due.Send.VariantID = variant.VariantID
+	}
+
+	msg, err := d.Render(due, variant)
+	if err != nil {
+		return err
+	}
+	sender := d.sender
+	if due.Mailbox != nil {
+		sender = d.mailboxSender(due.Mailbox)
+	}
+	if err := sender.Send(ctx, msg); err != nil {
+		return err
+	}
+	due.Send.MessageID = msg.MessageID
+	return nil
+}
+
+// Render builds the message for the current step of due, or variant of it
+// when not nil, filling in the contact's variables. Bodies containing markup
+// are sent as HTML, with the values escaped, anything else as plain text.
+// With click tracking on, links are rewritten to their tracking URLs. With
+// open tracking on, plain text bodies also get an HTML version to carry the
+// pixel.
+func (d *Delivery) Render(due *models.DueSend, variant *models.StepVariant) (*Message, error) {
+	fromEmail, fromName := d.conf.FromEmail, d.conf.FromName
+	if due.Mailbox != nil {
+		fromEmail, fromName = due.Mailbox.Email, due.Mailbox.FromName
+	}
+	messageId, err := NewMessageID(fromEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		MessageID: messageId,
+		FromEmail: fromEmail,
+		FromName:  fromName,
+		To:        due.Contact.Email,
+		Date:      d.now(),
+	}
+
+	subject, body := due.Step.VariantContent(variant)
+	vars := templating.ContactVariables(due.Contact)
+	msg.Subject, _, err = templating.Render(subject, vars, templating.NoEscape)
+	if err != nil {
+		return nil, err
+	}
+	if htmlTag.MatchString(body) {
+		msg.HTMLBody, _, err = templating.Render(body, vars, html.EscapeString)
+	} else {
+		msg.TextBody, _, err = templating.Render(body, vars, templating.NoEscape)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if due.Sequence.SequenceClickTrackingEnabled && d.tracker.Enabled() {
+		if msg.HTMLBody != "" {
+			msg.HTMLBody, err = d.tracker.RewriteHTMLLinks(msg.HTMLBody, trackingToken(due))
+		} else {
+			msg.TextBody, err = d.tracker.RewriteTextLinks(msg.TextBody, trackingToken(due))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if due.Sequence.SequenceOpenTrackingEnabled && d.tracker.Enabled() {
+		if msg.HTMLBody == "" {
+			msg.HTMLBody = textToHTML(msg.TextBody)
+		}
+		msg.HTMLBody, err = d.tracker.InjectPixel(msg.HTMLBody, trackingToken(due))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+func trackingToken(due *models.DueSend) tracking.Token {
+	return tracking.Token{
+		AccountID:    due.Enrollment.AccountID,
+		SequenceID:   due.Enrollment.SequenceID,
+		EnrollmentID: due.Enrollment.EnrollmentID,
+		StepID:       due.Step.StepID,
+	}
+}
+
+func textToHTML(text string) string {
+	return "<div>" + strings.ReplaceAll(html.EscapeString(text), "\n", "<br>\n") + "</div>"
+}
@@ -0,0 +1,209 @@
+package mail
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"salesforge-api/internal/config"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/tracking"
+	"strings"
+	"testing"
+)
+
+var noTracking = tracking.NewTracker(config.TrackingConfig{})
+
+var testTracker = tracking.NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com/", Secret: "secret"})
+
+func TestDeliver(t *testing.T) {
+	sender := NewMemorySender()
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com", FromName: "SDR"}, sender, noTracking)
+
+	due := &models.DueSend{
+		Step:    &models.Step{StepEmailSubject: "Welcome", StepEmailBody: "<p>Thanks for joining!</p>"},
+		Contact: models.Contact{Email: "jane.doe@example.com"},
+	}
+
+	err := delivery.Deliver(context.Background(), due)
+	assert.NoError(t, err)
+
+	messages := sender.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "jane.doe@example.com", messages[0].To)
+	assert.Equal(t, "Welcome", messages[0].Subject)
+	assert.Equal(t, "<p>Thanks for joining!</p>", messages[0].HTMLBody)
+	assert.Empty(t, messages[0].TextBody)
+	assert.Equal(t, messages[0].MessageID, due.Send.MessageID)
+}
+
+func TestRender_PlainText(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), noTracking)
+
+	msg, err := delivery.Render(&models.DueSend{
+		Step:    &models.Step{StepEmailSubject: "Welcome", StepEmailBody: "Thanks for joining, 2 < 3!"},
+		Contact: models.Contact{Email: "jane.doe@example.com"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Thanks for joining, 2 < 3!", msg.TextBody)
+	assert.Empty(t, msg.HTMLBody)
+}
+
+func TestDeliver_FromMailbox(t *testing.T) {
+	defaultSender := NewMemorySender()
+	mailboxSender := NewMemorySender()
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, defaultSender, noTracking)
+	delivery.mailboxSender = func(mailbox *models.Mailbox) Sender { return mailboxSender }
+
+	due := &models.DueSend{
+		Step:    &models.Step{StepEmailSubject: "Welcome", StepEmailBody: "Thanks for joining!"},
+		Contact: models.Contact{Email: "jane.doe@example.com"},
+		Mailbox: &models.Mailbox{MailboxID: 1, Email: "jon@sales.example.com", FromName: "Jon"},
+	}
+
+	err := delivery.Deliver(context.Background(), due)
+	assert.NoError(t, err)
+	assert.Empty(t, defaultSender.Messages())
+
+	messages := mailboxSender.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "jon@sales.example.com", messages[0].FromEmail)
+	assert.Equal(t, "Jon", messages[0].FromName)
+	assert.Contains(t, messages[0].MessageID, "@sales.example.com")
+}
+
+func TestRender_OpenTracking(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), testTracker)
+
+	due := &models.DueSend{
+		Enrollment: models.Enrollment{EnrollmentID: 3, AccountID: 1, SequenceID: 2},
+		Sequence:   models.Sequence{SequenceOpenTrackingEnabled: true},
+		Step:       &models.Step{StepID: 4, StepEmailSubject: "Welcome", StepEmailBody: "<html><body><p>Hi!</p></body></html>"},
+		Contact:    models.Contact{Email: "jane.doe@example.com"},
+	}
+
+	msg, err := delivery.Render(due, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg.HTMLBody, `<html><body><p>Hi!</p><img src="https://t.example.com/t/o/`))
+	assert.True(t, strings.HasSuffix(msg.HTMLBody, `</body></html>`))
+
+	signed := strings.TrimPrefix(msg.HTMLBody[strings.Index(msg.HTMLBody, "/t/o/"):], "/t/o/")
+	signed = signed[:strings.Index(signed, `"`)]
+	var token tracking.Token
+	assert.NoError(t, testTracker.Verify(tracking.PurposeOpen, signed, &token))
+	assert.Equal(t, tracking.Token{AccountID: 1, SequenceID: 2, EnrollmentID: 3, StepID: 4}, token)
+}
+
+func TestRender_OpenTrackingPlainText(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), testTracker)
+
+	msg, err := delivery.Render(&models.DueSend{
+		Sequence: models.Sequence{SequenceOpenTrackingEnabled: true},
+		Step:     &models.Step{StepEmailSubject: "Welcome", StepEmailBody: "Hi Jane,\nsee you soon & bye"},
+		Contact:  models.Contact{Email: "jane.doe@example.com"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Jane,\nsee you soon & bye", msg.TextBody)
+	assert.True(t, strings.HasPrefix(msg.HTMLBody, "<div>Hi Jane,<br>\nsee you soon &amp; bye</div><img "))
+}
+
+func TestRender_OpenTrackingDisabled(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), testTracker)
+
+	msg, err := delivery.Render(&models.DueSend{
+		Step:    &models.Step{StepEmailSubject: "Welcome", StepEmailBody: "<p>Hi!</p>"},
+		Contact: models.Contact{Email: "jane.doe@example.com"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Hi!</p>", msg.HTMLBody)
+}
+
+func TestRender_ClickTracking(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), testTracker)
+
+	msg, err := delivery.Render(&models.DueSend{
+		Sequence: models.Sequence{SequenceClickTrackingEnabled: true},
+		Step:     &models.Step{StepID: 4, StepEmailSubject: "Welcome", StepEmailBody: `<a href="https://example.com">Hi</a>`},
+		Contact:  models.Contact{Email: "jane.doe@example.com"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg.HTMLBody, `<a href="https://t.example.com/t/c/`))
+
+	msg, err = delivery.Render(&models.DueSend{
+		Sequence: models.Sequence{SequenceClickTrackingEnabled: true},
+		Step:     &models.Step{StepID: 4, StepEmailSubject: "Welcome", StepEmailBody: "Visit https://example.com"},
+		Contact:  models.Contact{Email: "jane.doe@example.com"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg.TextBody, "Visit https://t.example.com/t/c/"))
+	assert.Empty(t, msg.HTMLBody)
+}
+
+func TestRender_Variables(t *testing.T) {
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, NewMemorySender(), noTracking)
+
+	msg, err := delivery.Render(&models.DueSend{
+		Step: &models.Step{
+			StepEmailSubject: `Hi {{first_name | default:"there"}}`,
+			StepEmailBody:    `<p>How is {{company}}, {{custom.title}}?</p>`,
+		},
+		Contact: models.Contact{Email: "jane.doe@example.com", Company: "Smith & Sons", CustomFields: map[string]string{"title": "CTO"}},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi there", msg.Subject)
+	assert.Equal(t, "<p>How is Smith &amp; Sons, CTO?</p>", msg.HTMLBody)
+}
+
+func TestDeliver_Variant(t *testing.T) {
+	sender := NewMemorySender()
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, sender, noTracking)
+
+	due := &models.DueSend{
+		Step: &models.Step{
+			StepID:           1,
+			StepEmailSubject: "Welcome",
+			StepEmailBody:    "Thanks for joining!",
+			Variants: []models.StepVariant{
+				{VariantID: 3, StepEmailSubject: "Hi {{first_name}}", StepEmailBody: "Glad you're here", Weight: 1},
+			},
+		},
+		Contact: models.Contact{Email: "jane.doe@example.com", FirstName: "Jane"},
+	}
+
+	err := delivery.Deliver(context.Background(), due)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Jane", sender.Messages()[0].Subject)
+	assert.Equal(t, "Glad you're here", sender.Messages()[0].TextBody)
+	assert.Equal(t, int64(3), due.Send.VariantID)
+}
+
+func TestDeliver_RetriesRecordedVariant(t *testing.T) {
+	sender := NewMemorySender()
+	delivery := NewDelivery(config.SmtpConfig{FromEmail: "sdr@example.com"}, sender, noTracking)
+
+	due := &models.DueSend{
+		Step: &models.Step{
+			StepID:            1,
+			StepEmailSubject:  "Welcome",
+			StepEmailBody:     "Thanks for joining!",
+			PromotedVariantID: 4,
+			Variants: []models.StepVariant{
+				{VariantID: 3, StepEmailSubject: "Variant A", StepEmailBody: "Body A", Weight: 1},
+				{VariantID: 4, StepEmailSubject: "Variant B", StepEmailBody: "Body B", Weight: 1},
+			},
+		},
+		Send:    models.Send{VariantID: 3, Attempts: 2},
+		Contact: models.Contact{Email: "jane.doe@example.com"},
+	}
+
+	// The first attempt went out as A before B was promoted.
+	err := delivery.Deliver(context.Background(), due)
+	assert.NoError(t, err)
+	assert.Equal(t, "Variant A", sender.Messages()[0].Subject)
+	assert.Equal(t, int64(3), due.Send.VariantID)
+
+	// A variant removed since falls back to the contact's current one.
+	due.Send.VariantID = 9
+	err = delivery.Deliver(context.Background(), due)
+	assert.NoError(t, err)
+	assert.Equal(t, "Variant B", sender.Messages()[1].Subject)
+	assert.Equal(t, int64(4), due.Send.VariantID)
+}
@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is a rendered email. At least one of TextBody and HTMLBody is set;
+// with both it is sent as multipart/alternative.
+type Message struct {
+	MessageID string
+	FromEmail string
+	FromName  string
+	To        string
+	Subject   string
+	TextBody  string
+	HTMLBody  string
+	Date      time.Time
+}
+
+// NewMessageID returns a unique Message-ID, without angle brackets, in the
+// domain of the sender address.
+func NewMessageID(fromEmail string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(fromEmail, "@"); i >= 0 {
+		domain = fromEmail[i+1:]
+	}
+	return hex.EncodeToString(b) + "@" + domain, nil
+}
+
+// Bytes formats the message as RFC 5322 with quoted-printable bodies.
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	from := mail.Address{Name: m.FromName, Address: m.FromEmail}
+	to := mail.Address{Address: m.To}
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", m.Date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s>\r\n", m.MessageID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	switch {
+	case m.TextBody != "" && m.HTMLBody != "":
+		mw := multipart.NewWriter(&buf)
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", m.TextBody},
+			{"text/html", m.HTMLBody},
+		} {
+			w, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType + "; charset=utf-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.body); err != nil {
+				return nil, err
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	case m.HTMLBody != "":
+		buf.WriteString("Content-Type: text/html; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, m.HTMLBody); err != nil {
+			return nil, err
+		}
+	default:
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, m.TextBody); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageBytes_PlainText(t *testing.T) {
+	msg := &Message{
+		MessageID: "abc@example.com",
+		FromEmail: "sdr@example.com",
+		FromName:  "Šime Sales",
+		To:        "jane.doe@example.com",
+		Subject:   "Pozdrav, Jane",
+		TextBody:  "Hi Jane,\nwelcome aboard.",
+		Date:      time.Unix(1737000000, 0),
+	}
+
+	b, err := msg.Bytes()
+	assert.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(b)))
+	assert.NoError(t, err)
+	assert.Equal(t, "<abc@example.com>", parsed.Header.Get("Message-ID"))
+	from, err := parsed.Header.AddressList("From")
+	assert.NoError(t, err)
+	assert.Equal(t, "Šime Sales", from[0].Name)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Pozdrav, Jane", subject)
+	assert.True(t, strings.HasPrefix(parsed.Header.Get("Content-Type"), "text/plain"))
+}
+
+func TestMessageBytes_Alternative(t *testing.T) {
+	msg := &Message{
+		MessageID: "abc@example.com",
+		FromEmail: "sdr@example.com",
+		To:        "jane.doe@example.com",
+		Subject:   "Hello",
+		TextBody:  "Hello",
+		HTMLBody:  "<p>Hello</p>",
+		Date:      time.Unix(1737000000, 0),
+	}
+
+	b, err := msg.Bytes()
+	assert.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(b)))
+	assert.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var contentTypes []string
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, contentTypes)
+}
+
+func TestNewMessageID(t *testing.T) {
+	first, err := NewMessageID("sdr@example.com")
+	assert.NoError(t, err)
+	second, err := NewMessageID("sdr@example.com")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(first, "@example.com"))
+	assert.NotEqual(t, first, second)
+}
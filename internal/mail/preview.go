@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"html"
+	"regexp"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/templating"
+	"sort"
+	"strings"
+)
+
+var (
+	htmlHidden     = regexp.MustCompile(`(?is)<(head|style|script)\b.*?</(head|style|script)>`)
+	htmlLineBreak  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlAnyTag     = regexp.MustCompile(`<[^>]*>`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+	trailingSpaces = regexp.MustCompile(`[ \t]+\n`)
+)
+
+// Preview is a step rendered for a contact in both formats, without
+// tracking. VariantID is the variant the contact gets, if the step has any.
+type Preview struct {
+	VariantID        int64
+	Subject          string
+	HTMLBody         string
+	TextBody         string
+	MissingVariables []string
+}
+
+// RenderPreview renders step for contact. HTML bodies get a plain text
+// version with the markup stripped and plain text ones an HTML version, as
+// mail clients would show either.
+func RenderPreview(step models.Step, contact models.Contact) (*Preview, error) {
+	vars := templating.ContactVariables(contact)
+	subject, body, variantId := step.Content(contact.Email)
+	preview := &Preview{VariantID: variantId}
+
+	subject, missingSubject, err := templating.Render(subject, vars, templating.NoEscape)
+	if err != nil {
+		return nil, err
+	}
+	preview.Subject = subject
+
+	var missingBody []string
+	if htmlTag.MatchString(body) {
+		preview.HTMLBody, missingBody, err = templating.Render(body, vars, html.EscapeString)
+		preview.TextBody = htmlToText(preview.HTMLBody)
+	} else {
+		preview.TextBody, missingBody, err = templating.Render(body, vars, templating.NoEscape)
+		preview.HTMLBody = textToHTML(preview.TextBody)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	preview.MissingVariables = mergeMissing(missingSubject, missingBody)
+	return preview, nil
+}
+
+func mergeMissing(a, b []string) []string {
+	seen := map[string]bool{}
+	missing := []string{}
+	for _, name := range append(a, b...) {
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func htmlToText(body string) string {
+	text := htmlHidden.ReplaceAllString(body, "")
+	text = htmlLineBreak.ReplaceAllStringFunc(text, func(s string) string { return s + "\n" })
+	text = htmlAnyTag.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = trailingSpaces.ReplaceAllString(text, "\n")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"salesforge-api/internal/models"
+	"testing"
+)
+
+func TestRenderPreview_HTML(t *testing.T) {
+	step := models.Step{
+		StepEmailSubject: `Hi {{first_name | default:"there"}}`,
+		StepEmailBody:    "<html><head><style>p {}</style></head><body><p>Hello {{first_name}},</p><p>How is {{company}}?<br>{{custom.title}}</p></body></html>",
+	}
+
+	preview, err := RenderPreview(step, models.Contact{Company: "Smith & Sons"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi there", preview.Subject)
+	assert.Contains(t, preview.HTMLBody, "<p>How is Smith &amp; Sons?<br></p>")
+	assert.Equal(t, "Hello ,\nHow is Smith & Sons?", preview.TextBody)
+	assert.Equal(t, []string{"custom.title", "first_name"}, preview.MissingVariables)
+}
+
+func TestRenderPreview_PlainText(t *testing.T) {
+	step := models.Step{StepEmailSubject: "Hi {{first_name}}", StepEmailBody: "Hello {{first_name}},\nbye"}
+
+	preview, err := RenderPreview(step, models.Contact{FirstName: "Jane"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Jane", preview.Subject)
+	assert.Equal(t, "Hello Jane,\nbye", preview.TextBody)
+	assert.Equal(t, "<div>Hello Jane,<br>\nbye</div>", preview.HTMLBody)
+	assert.Empty(t, preview.MissingVariables)
+}
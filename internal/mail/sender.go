@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"salesforge-api/internal/config"
+	"sync"
+)
+
+// Sender delivers rendered messages.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewSender returns the sender selected by the config's driver.
+func NewSender(conf config.SmtpConfig) (Sender, error) {
+	switch conf.Driver {
+	case config.SmtpDriverSmtp:
+		return NewSMTPSender(conf), nil
+	case config.SmtpDriverFile:
+		return NewFileSender(conf.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown smtp driver %q", conf.Driver)
+	}
+}
+
+// FileSender writes every message to Dir as an .eml file named after its
+// Message-ID, for local development.
+type FileSender struct {
+	Dir string
+}
+
+func NewFileSender(dir string) *FileSender {
+	return &FileSender{Dir: dir}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	b, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, msg.MessageID+".eml"), b, 0o644)
+}
+
+// MemorySender keeps sent messages in memory, for tests.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(ctx context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (s *MemorySender) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
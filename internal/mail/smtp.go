@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"salesforge-api/internal/config"
+	"strconv"
+	"time"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPSender delivers messages over SMTP, upgrading the connection with
+// STARTTLS and authenticating with AUTH PLAIN when a username is set.
+type SMTPSender struct {
+	conf config.SmtpConfig
+}
+
+func NewSMTPSender(conf config.SmtpConfig) *SMTPSender {
+	return &SMTPSender{conf: conf}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	b, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.conf.Host, strconv.Itoa(s.conf.Port))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, s.conf.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.conf.Host}); err != nil {
+			return fmt.Errorf("starttls failed: %w", err)
+		}
+	} else if s.conf.StartTLS {
+		return fmt.Errorf("smtp server does not support starttls")
+	}
+
+	if s.conf.Username != "" {
+		auth := smtp.PlainAuth("", s.conf.Username, s.conf.Password, s.conf.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(msg.FromEmail); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"salesforge-api/internal/config"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts a single session, advertising AUTH PLAIN, and
+// records the credentials and the message it was given.
+type fakeSMTPServer struct {
+	listener net.Listener
+	auth     chan string
+	data     chan string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: l, auth: make(chan string, 1), data: make(chan string, 1)}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(line, "AUTH PLAIN "):
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.auth <- string(decoded)
+			reply("235 Authentication succeeded")
+		case strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+			reply("250 OK")
+		case line == "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data <- data.String()
+			reply("250 OK")
+		case line == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	port, _ := strconv.Atoi(strings.Split(server.listener.Addr().String(), ":")[1])
+
+	sender := NewSMTPSender(config.SmtpConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "sdr",
+		Password: "secret",
+	})
+	msg := &Message{
+		MessageID: "abc@example.com",
+		FromEmail: "sdr@example.com",
+		To:        "jane.doe@example.com",
+		Subject:   "Welcome",
+		TextBody:  "Thanks for joining!",
+		Date:      time.Unix(1737000000, 0),
+	}
+
+	err := sender.Send(context.Background(), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "\x00sdr\x00secret", <-server.auth)
+	data := <-server.data
+	assert.Contains(t, data, "Message-ID: <abc@example.com>")
+	assert.Contains(t, data, "Thanks for joining!")
+}
+
+func TestSMTPSender_RequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	port, _ := strconv.Atoi(strings.Split(server.listener.Addr().String(), ":")[1])
+
+	sender := NewSMTPSender(config.SmtpConfig{Host: "127.0.0.1", Port: port, StartTLS: true})
+	err := sender.Send(context.Background(), &Message{MessageID: "abc@example.com", FromEmail: "sdr@example.com", To: "jane.doe@example.com", TextBody: "Hi"})
+	assert.ErrorContains(t, err, "starttls")
+}
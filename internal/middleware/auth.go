@@ -1,38 +1,206 @@
 package middleware
 
 import (
-	"github.com/golang-jwt/jwt"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
 	"net/http"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/monitoring"
 	"strings"
 )
 
-var jwtKey = []byte("salesforge_secret_key")
+const (
+	authRealm = "salesforge-api"
+	// APIKeyHeader carries API keys; APIKeyScheme names them in challenges.
+	APIKeyHeader = "X-API-Key"
+	APIKeyScheme = "APIKey"
+)
 
-func Authenticate(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
-		}
+// Reasons a request is rejected, used as the metric label and log field.
+const (
+	AuthReasonMissingCredentials = "missing_credentials"
+	AuthReasonMalformedHeader    = "malformed_header"
+	AuthReasonMalformedToken     = "malformed_token"
+	AuthReasonAlgorithm          = "algorithm_not_allowed"
+	AuthReasonUnknownKey         = "unknown_key"
+	AuthReasonKeysUnavailable    = "keys_unavailable"
+	AuthReasonInvalidSignature   = "invalid_signature"
+	AuthReasonExpired            = "expired"
+	AuthReasonNotYetValid        = "not_yet_valid"
+	AuthReasonIssuer             = "invalid_issuer"
+	AuthReasonAudience           = "invalid_audience"
+	AuthReasonInvalidAPIKey      = "invalid_api_key"
+	AuthReasonRevokedAPIKey      = "revoked_api_key"
+	AuthReasonNoAccount          = "no_account"
+	AuthReasonPermissionDenied   = "permission_denied"
+)
+
+// APIKeyAuthenticator resolves the caller of an X-API-Key header.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*auth.Identity, error)
+}
+
+type authenticator struct {
+	verifier *JWTVerifier
+	apiKeys  APIKeyAuthenticator
+	logger   *zap.Logger
+}
 
-		tokenString := strings.Split(authHeader, " ")[1]
-		claims := &Claims{}
+// Authenticate scopes the request to the account of its bearer token, or of
+// its X-API-Key when apiKeys is set. A nil verifier turns bearer tokens off.
+// Missing, invalid and expired credentials get a 401 with a WWW-Authenticate
+// challenge; valid ones the request can't be served with get a 403.
+func Authenticate(verifier *JWTVerifier, apiKeys APIKeyAuthenticator, logger *zap.Logger) func(http.Handler) http.Handler {
+	a := &authenticator{
+		verifier: verifier,
+		apiKeys:  apiKeys,
+		logger:   logger,
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var identity *auth.Identity
+			var ok bool
+			if key := r.Header.Get(APIKeyHeader); key != "" && a.apiKeys != nil {
+				identity, ok = a.authenticateAPIKey(w, r, key)
+			} else {
+				identity, ok = a.authenticateBearer(w, r)
+			}
+			if !ok {
+				return
+			}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
+			ctx := auth.NewContext(r.Context(), identity)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
+	}
+}
+
+func (a *authenticator) authenticateBearer(w http.ResponseWriter, r *http.Request) (*auth.Identity, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" || a.verifier == nil {
+		a.unauthorized(w, r, AuthReasonMissingCredentials, errors.New("no credentials"))
+		return nil, false
+	}
+
+	tokenString, ok := bearerToken(authHeader)
+	if !ok {
+		a.unauthorized(w, r, AuthReasonMalformedHeader, errors.New("not a bearer authorization header"))
+		return nil, false
+	}
+
+	claims, err := a.verifier.Verify(tokenString)
+	if err != nil {
+		a.unauthorized(w, r, tokenRejectionReason(err), err)
+		return nil, false
+	}
+
+	if claims.AccountID <= 0 {
+		a.forbidden(w, r, AuthReasonNoAccount, errors.New("token has no account_id"))
+		return nil, false
+	}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
+	return claims.Identity(), true
+}
+
+func (a *authenticator) authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string) (*auth.Identity, bool) {
+	identity, err := a.apiKeys.AuthenticateAPIKey(r.Context(), key)
+	switch {
+	case errors.Is(err, auth.ErrRevokedAPIKey):
+		a.unauthorized(w, r, AuthReasonRevokedAPIKey, err)
+		return nil, false
+	case errors.Is(err, auth.ErrUnauthenticated):
+		a.unauthorized(w, r, AuthReasonInvalidAPIKey, err)
+		return nil, false
+	case err != nil:
+		a.logger.Error("error authenticating api key", zap.Error(err))
+		sfErr.WriteProblem(w, r, err)
+		return nil, false
+	}
+
+	return identity, true
+}
+
+// bearerToken returns the token of an Authorization header using the Bearer
+// scheme, whose name is case-insensitive.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func tokenRejectionReason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenAlgorithm):
+		return AuthReasonAlgorithm
+	case errors.Is(err, ErrTokenUnknownKey):
+		return AuthReasonUnknownKey
+	case errors.Is(err, ErrJWKSUnavailable):
+		return AuthReasonKeysUnavailable
+	case errors.Is(err, ErrTokenSignature):
+		return AuthReasonInvalidSignature
+	case errors.Is(err, ErrTokenExpired):
+		return AuthReasonExpired
+	case errors.Is(err, ErrTokenNotYetValid):
+		return AuthReasonNotYetValid
+	case errors.Is(err, ErrTokenIssuer):
+		return AuthReasonIssuer
+	case errors.Is(err, ErrTokenAudience):
+		return AuthReasonAudience
+	}
+	return AuthReasonMalformedToken
+}
+
+func (a *authenticator) unauthorized(w http.ResponseWriter, r *http.Request, reason string, err error) {
+	var message, tokenError string
+	switch reason {
+	case AuthReasonMissingCredentials:
+		message = "missing credentials"
+	case AuthReasonMalformedHeader:
+		message, tokenError = "malformed authorization header", "invalid_request"
+	case AuthReasonExpired:
+		message, tokenError = "bearer token expired", "invalid_token"
+	case AuthReasonInvalidAPIKey:
+		message = "invalid api key"
+	case AuthReasonRevokedAPIKey:
+		message = "api key revoked"
+	default:
+		message, tokenError = "invalid bearer token", "invalid_token"
+	}
+
+	// Requests without credentials only get the challenges (RFC 6750).
+	// Errors are described in the challenge of the scheme that was used.
+	usedAPIKey := reason == AuthReasonInvalidAPIKey || reason == AuthReasonRevokedAPIKey
+	if a.verifier != nil && !usedAPIKey {
+		challenge := fmt.Sprintf("Bearer realm=%q", authRealm)
+		if tokenError != "" {
+			challenge += fmt.Sprintf(", error=%q, error_description=%q", tokenError, message)
 		}
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	if a.apiKeys != nil && (usedAPIKey || reason == AuthReasonMissingCredentials) {
+		w.Header().Add("WWW-Authenticate", fmt.Sprintf("%s realm=%q", APIKeyScheme, authRealm))
+	}
+	a.reject(w, r, reason, sfErr.NewAppError(http.StatusUnauthorized, message, err))
+}
 
-		next.ServeHTTP(w, r)
-	})
+func (a *authenticator) forbidden(w http.ResponseWriter, r *http.Request, reason string, err error) {
+	a.reject(w, r, reason, sfErr.NewAppError(http.StatusForbidden, "not allowed to access this resource", err))
 }
 
-type Claims struct {
-	Username string `json:"username"`
-	jwt.StandardClaims
+func (a *authenticator) reject(w http.ResponseWriter, r *http.Request, reason string, err *sfErr.AppError) {
+	monitoring.RecordAuthRejection(reason)
+	a.logger.Info("request rejected",
+		zap.String("method", r.Method),
+		zap.String("url", r.URL.String()),
+		zap.Int("status", err.Code),
+		zap.String("reason", reason),
+		zap.Error(err.Err),
+	)
+	sfErr.WriteProblem(w, r, err)
 }
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/config"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+func testVerifier(t *testing.T) *JWTVerifier {
+	t.Setenv("TEST_JWT_SECRET", testSecret)
+	verifier, err := NewJWTVerifier(config.JWTConfig{
+		Algorithms: []string{config.JWTAlgorithmHS256},
+		SecretEnv:  "TEST_JWT_SECRET",
+	})
+	assert.NoError(t, err)
+	return verifier
+}
+
+func signedToken(t *testing.T, claims *Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	var identity *auth.Identity
+	handler := Authenticate(testVerifier(t), nil, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = auth.FromContext(r.Context())
+	}))
+
+	token := signedToken(t, &Claims{
+		AccountID:      1,
+		UserID:         "u-1",
+		Roles:          []string{"editor"},
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	})
+	r := httptest.NewRequest(http.MethodGet, "/v1/sequences", nil)
+	r.Header.Set("Authorization", "bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &auth.Identity{AccountID: 1, UserID: "u-1", Roles: []string{"editor"}}, identity)
+}
+
+func TestAuthenticate_NoAccount(t *testing.T) {
+	handler := Authenticate(testVerifier(t), nil, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("unexpected request")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/sequences", nil)
+	r.Header.Set("Authorization", "Bearer "+signedToken(t, &Claims{Username: "jane"}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthenticate_Unauthorized(t *testing.T) {
+	expired := signedToken(t, &Claims{
+		AccountID:      1,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+	})
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{AccountID: 1}).SignedString([]byte("other-secret"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		header        string
+		wantReason    string
+		wantChallenge string
+	}{
+		{
+			name:          "missing header",
+			header:        "",
+			wantReason:    AuthReasonMissingCredentials,
+			wantChallenge: `Bearer realm="salesforge-api"`,
+		},
+		{
+			name:          "no space",
+			header:        "Bearer",
+			wantReason:    AuthReasonMalformedHeader,
+			wantChallenge: `Bearer realm="salesforge-api", error="invalid_request", error_description="malformed authorization header"`,
+		},
+		{
+			name:          "other scheme",
+			header:        "Basic dXNlcjpwYXNz",
+			wantReason:    AuthReasonMalformedHeader,
+			wantChallenge: `Bearer realm="salesforge-api", error="invalid_request", error_description="malformed authorization header"`,
+		},
+		{
+			name:          "malformed token",
+			header:        "Bearer abc",
+			wantReason:    AuthReasonMalformedToken,
+			wantChallenge: `Bearer realm="salesforge-api", error="invalid_token", error_description="invalid bearer token"`,
+		},
+		{
+			name:          "forged token",
+			header:        "Bearer " + forged,
+			wantReason:    AuthReasonInvalidSignature,
+			wantChallenge: `Bearer realm="salesforge-api", error="invalid_token", error_description="invalid bearer token"`,
+		},
+		{
+			name:          "expired token",
+			header:        "BEARER " + expired,
+			wantReason:    AuthReasonExpired,
+			wantChallenge: `Bearer realm="salesforge-api", error="invalid_token", error_description="bearer token expired"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			handler := Authenticate(testVerifier(t), nil, zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("unexpected request")
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/sequences", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, sfErr.ProblemContentType, w.Header().Get("Content-Type"))
+			entries := logs.FilterMessage("request rejected").All()
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, tt.wantReason, entries[0].ContextMap()["reason"])
+			}
+		})
+	}
+}
+
+type apiKeyAuthenticatorFunc func(ctx context.Context, key string) (*auth.Identity, error)
+
+func (f apiKeyAuthenticatorFunc) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Identity, error) {
+	return f(ctx, key)
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	apiKeys := apiKeyAuthenticatorFunc(func(ctx context.Context, key string) (*auth.Identity, error) {
+		switch key {
+		case "sf_read_secret":
+			return &auth.Identity{AccountID: 1, APIKeyID: 1, Scopes: []string{models.APIKeyScopeRead}}, nil
+		case "sf_write_secret":
+			return &auth.Identity{AccountID: 1, APIKeyID: 2, Scopes: []string{models.APIKeyScopeWrite}}, nil
+		case "sf_revoked_secret":
+			return nil, auth.ErrRevokedAPIKey
+		}
+		return nil, auth.ErrInvalidAPIKey
+	})
+
+	tests := []struct {
+		name          string
+		method        string
+		apiKey        string
+		bearer        bool
+		wantCode      int
+		wantAPIKeyID  int64
+		wantChallenge []string
+	}{
+		{name: "read key reads", method: http.MethodGet, apiKey: "sf_read_secret", wantCode: http.StatusOK, wantAPIKeyID: 1},
+		{name: "write key writes", method: http.MethodPost, apiKey: "sf_write_secret", wantCode: http.StatusOK, wantAPIKeyID: 2},
+		{
+			name:          "revoked key",
+			method:        http.MethodGet,
+			apiKey:        "sf_revoked_secret",
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`APIKey realm="salesforge-api"`},
+		},
+		{
+			name:          "unknown key",
+			method:        http.MethodGet,
+			apiKey:        "sf_other_secret",
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`APIKey realm="salesforge-api"`},
+		},
+		{name: "bearer token", method: http.MethodGet, bearer: true, wantCode: http.StatusOK},
+		{
+			name:          "no credentials",
+			method:        http.MethodGet,
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`Bearer realm="salesforge-api"`, `APIKey realm="salesforge-api"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var identity *auth.Identity
+			handler := Authenticate(testVerifier(t), apiKeys, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity, _ = auth.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(tt.method, "/v1/sequences", nil)
+			if tt.apiKey != "" {
+				r.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.bearer {
+				r.Header.Set("Authorization", "Bearer "+signedToken(t, &Claims{AccountID: 1}))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantChallenge, w.Header().Values("WWW-Authenticate"))
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, int64(1), identity.AccountID)
+				assert.Equal(t, tt.wantAPIKeyID, identity.APIKeyID)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"net/http"
 	sfErr "salesforge-api/internal/errors"
@@ -15,10 +16,10 @@ func ErrorHandlingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler
 					var appErr *sfErr.AppError
 					if err, ok := rec.(error); ok && errors.As(err, &appErr) {
 						logger.Error("handled error", zap.Error(appErr))
-						http.Error(w, appErr.Message, appErr.Code)
+						sfErr.WriteProblem(w, r, appErr)
 					} else {
 						logger.Error("unhandled error", zap.Any("error", rec))
-						http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+						sfErr.WriteProblem(w, r, fmt.Errorf("panic: %v", rec))
 					}
 				}
 			}()
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"salesforge-api/internal/config"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSCacheTTL = 5 * time.Minute
+	// jwksMinRefreshInterval limits how often tokens naming an unknown key
+	// can make the JWKS be fetched again.
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+var ErrJWKSUnavailable = errors.New("jwks unavailable")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksKey struct {
+	kid string
+	alg string
+	key interface{}
+}
+
+// jwks caches the keys published at a JWKS URL. The whole set is replaced on
+// every fetch, so keys removed by a rotation stop being accepted once the
+// cache expires.
+type jwks struct {
+	url         string
+	ttl         time.Duration
+	client      *http.Client
+	now         func() time.Time
+	mu          sync.Mutex
+	keys        []jwksKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func newJWKS(url string, ttl time.Duration) *jwks {
+	if ttl == 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	return &jwks{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// lookup returns the cached keys usable with alg, only the one of kid if it
+// is set. An unknown kid makes the keys be fetched again, as the issuer may
+// have rotated them.
+func (s *jwks) lookup(alg, kid string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var err error
+	if now.Sub(s.fetchedAt) >= s.ttl && now.Sub(s.attemptedAt) >= jwksMinRefreshInterval {
+		err = s.refresh(now)
+	}
+	keys := s.match(alg, kid)
+	if len(keys) == 0 && kid != "" && now.Sub(s.attemptedAt) >= jwksMinRefreshInterval {
+		err = s.refresh(now)
+		keys = s.match(alg, kid)
+	}
+	if len(keys) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenUnknownKey
+	}
+	return keys, nil
+}
+
+func (s *jwks) match(alg, kid string) []interface{} {
+	var keys []interface{}
+	for _, k := range s.keys {
+		if kid != "" && k.kid != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		if keyMatches(alg, k.key) {
+			keys = append(keys, k.key)
+		}
+	}
+	return keys
+}
+
+// refresh fetches the keys, keeping the cached ones if that fails.
+func (s *jwks) refresh(now time.Time) error {
+	s.attemptedAt = now
+	res, err := s.client.Get(s.url)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", ErrJWKSUnavailable, res.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+
+	keys := make([]jwksKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of unsupported types are skipped, not fatal.
+			continue
+		}
+		keys = append(keys, jwksKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	s.keys = keys
+	s.fetchedAt = now
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" || (k.Alg != "" && k.Alg != config.JWTAlgorithmES256) {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"os"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/config"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenMalformed   = errors.New("malformed token")
+	ErrTokenAlgorithm   = errors.New("token signing algorithm not allowed")
+	ErrTokenUnknownKey  = errors.New("no key to verify token")
+	ErrTokenSignature   = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrTokenIssuer      = errors.New("invalid token issuer")
+	ErrTokenAudience    = errors.New("invalid token audience")
+)
+
+type Claims struct {
+	Username  string   `json:"username"`
+	AccountID int64    `json:"account_id"`
+	UserID    string   `json:"user_id"`
+	Roles     []string `json:"roles"`
+	// Audience shadows the one in StandardClaims, which can't hold a list.
+	Audience Audience `json:"aud,omitempty"`
+	jwt.StandardClaims
+}
+
+func (c *Claims) Identity() *auth.Identity {
+	return &auth.Identity{
+		AccountID: c.AccountID,
+		UserID:    c.UserID,
+		Roles:     c.Roles,
+	}
+}
+
+// Audience is the aud claim, which may be a single string or a list.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTVerifier checks the signature and claims of bearer tokens. Tokens must
+// be signed with one of the configured algorithms, by a key of the matching
+// type; the algorithm named in a token never decides how it's verified.
+type JWTVerifier struct {
+	algorithms []string
+	secrets    [][]byte
+	publicKeys []interface{}
+	jwks       *jwks
+	issuer     string
+	audience   string
+	clockSkew  time.Duration
+	now        func() time.Time
+}
+
+func NewJWTVerifier(conf config.JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		algorithms: conf.Algorithms,
+		issuer:     conf.Issuer,
+		audience:   conf.Audience,
+		clockSkew:  time.Duration(conf.ClockSkewSeconds) * time.Second,
+		now:        time.Now,
+	}
+
+	if conf.SecretFile != "" {
+		b, err := os.ReadFile(conf.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt secret file: %w", err)
+		}
+		// One secret per line, so that a new secret can be added before the
+		// old one is dropped.
+		for _, line := range strings.Split(string(b), "\n") {
+			if secret := strings.TrimSpace(line); secret != "" {
+				v.secrets = append(v.secrets, []byte(secret))
+			}
+		}
+	}
+	if conf.SecretEnv != "" {
+		secret := os.Getenv(conf.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("jwt secret env %s is not set", conf.SecretEnv)
+		}
+		v.secrets = append(v.secrets, []byte(secret))
+	}
+
+	for _, path := range conf.PublicKeyFiles {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt public key file: %w", err)
+		}
+		key, err := parsePublicKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt public key file %s: %w", path, err)
+		}
+		v.publicKeys = append(v.publicKeys, key)
+	}
+
+	if conf.JWKSURL != "" {
+		v.jwks = newJWKS(conf.JWKSURL, time.Duration(conf.JWKSCacheSeconds)*time.Second)
+	}
+
+	return v, nil
+}
+
+func parsePublicKey(b []byte) (interface{}, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(b); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM(b)
+	if err != nil {
+		return nil, errors.New("not an RSA or EC public key")
+	}
+	return key, nil
+}
+
+// Verify returns the claims of tokenString once its signature, expiry,
+// issuer and audience check out.
+func (v *JWTVerifier) Verify(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, parts, err := new(jwt.Parser).ParseUnverified(tokenString, claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+
+	alg := token.Method.Alg()
+	if !v.allowed(alg) {
+		return nil, fmt.Errorf("%w: %s", ErrTokenAlgorithm, alg)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	keys, err := v.keys(alg, kid)
+	if err != nil {
+		return nil, err
+	}
+	signingString := parts[0] + "." + parts[1]
+	verified := false
+	for _, key := range keys {
+		if token.Method.Verify(signingString, parts[2], key) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrTokenSignature
+	}
+
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) allowed(alg string) bool {
+	for _, a := range v.algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// keys lists the keys a token signed with alg may have been signed with.
+// With a kid, only the JWKS key of that id is used besides the static keys.
+func (v *JWTVerifier) keys(alg, kid string) ([]interface{}, error) {
+	var keys []interface{}
+	switch alg {
+	case config.JWTAlgorithmHS256:
+		for _, secret := range v.secrets {
+			keys = append(keys, secret)
+		}
+	case config.JWTAlgorithmRS256, config.JWTAlgorithmES256:
+		for _, key := range v.publicKeys {
+			if keyMatches(alg, key) {
+				keys = append(keys, key)
+			}
+		}
+		if v.jwks != nil {
+			jwksKeys, err := v.jwks.lookup(alg, kid)
+			if err != nil && len(keys) == 0 {
+				return nil, err
+			}
+			keys = append(keys, jwksKeys...)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrTokenUnknownKey
+	}
+	return keys, nil
+}
+
+func keyMatches(alg string, key interface{}) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return alg == config.JWTAlgorithmRS256
+	case *ecdsa.PublicKey:
+		return alg == config.JWTAlgorithmES256 && k.Curve == elliptic.P256()
+	}
+	return false
+}
+
+func (v *JWTVerifier) validate(claims *Claims) error {
+	now := v.now()
+	if claims.ExpiresAt != 0 && now.Add(-v.clockSkew).Unix() > claims.ExpiresAt {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(v.clockSkew).Unix() < claims.NotBefore {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != 0 && now.Add(v.clockSkew).Unix() < claims.IssuedAt {
+		return ErrTokenNotYetValid
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return ErrTokenIssuer
+	}
+	if v.audience != "" && !claims.Audience.Contains(v.audience) {
+		return ErrTokenAudience
+	}
+	return nil
+}
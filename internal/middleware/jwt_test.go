@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"salesforge-api/internal/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: config.JWTAlgorithmRS256,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signWithKid(t *testing.T, method jwt.SigningMethod, kid string, claims *Claims, key interface{}) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return s
+}
+
+// jwksServer serves the keys it's given, counting the fetches.
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    []jwk
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+}
+
+func (s *jwksServer) set(keys ...jwk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func TestJWTVerifier_JWKS(t *testing.T) {
+	rsaKey1, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rsaKey2, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	keys := &jwksServer{}
+	keys.set(rsaJWK("k1", rsaKey1), ecJWK("e1", ecKey))
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(config.JWTConfig{
+		Algorithms:       []string{config.JWTAlgorithmRS256, config.JWTAlgorithmES256},
+		JWKSURL:          server.URL,
+		JWKSCacheSeconds: 3600,
+	})
+	assert.NoError(t, err)
+	now := time.Now()
+	verifier.jwks.now = func() time.Time { return now }
+
+	claims := &Claims{AccountID: 1}
+	got, err := verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "k1", claims, rsaKey1))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.AccountID)
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodES256, "e1", claims, ecKey))
+	assert.NoError(t, err)
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "", claims, rsaKey1))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, keys.fetches)
+
+	// A token signed with the key of another kid is rejected.
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "k1", claims, rsaKey2))
+	assert.ErrorIs(t, err, ErrTokenSignature)
+
+	// The issuer rotates to k2; the unknown kid makes the keys be fetched
+	// again and k1 is no longer accepted.
+	keys.set(rsaJWK("k2", rsaKey2))
+	now = now.Add(jwksMinRefreshInterval)
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "k2", claims, rsaKey2))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.fetches)
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "k1", claims, rsaKey1))
+	assert.ErrorIs(t, err, ErrTokenUnknownKey)
+
+	// Unknown kids don't make the keys be fetched more often than allowed.
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "k3", claims, rsaKey1))
+	assert.ErrorIs(t, err, ErrTokenUnknownKey)
+	assert.Equal(t, 2, keys.fetches)
+}
+
+func TestJWTVerifier_Algorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(keyFile, publicPEM, 0o600))
+
+	verifier, err := NewJWTVerifier(config.JWTConfig{
+		Algorithms:     []string{config.JWTAlgorithmRS256},
+		PublicKeyFiles: []string{keyFile},
+	})
+	assert.NoError(t, err)
+
+	claims := &Claims{AccountID: 1}
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodRS256, "", claims, rsaKey))
+	assert.NoError(t, err)
+
+	// The public key must not be usable as an HMAC secret.
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodHS256, "", claims, publicPEM))
+	assert.ErrorIs(t, err, ErrTokenAlgorithm)
+
+	none := signWithKid(t, jwt.SigningMethodNone, "", claims, jwt.UnsafeAllowNoneSignatureType)
+	_, err = verifier.Verify(none)
+	assert.ErrorIs(t, err, ErrTokenAlgorithm)
+
+	_, err = verifier.Verify("not-a-token")
+	assert.ErrorIs(t, err, ErrTokenMalformed)
+}
+
+func TestJWTVerifier_SecretFile(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secrets")
+	assert.NoError(t, os.WriteFile(secretFile, []byte("new-secret\nold-secret\n"), 0o600))
+
+	verifier, err := NewJWTVerifier(config.JWTConfig{
+		Algorithms: []string{config.JWTAlgorithmHS256},
+		SecretFile: secretFile,
+	})
+	assert.NoError(t, err)
+
+	claims := &Claims{AccountID: 1}
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodHS256, "", claims, []byte(secret)))
+		assert.NoError(t, err, secret)
+	}
+	_, err = verifier.Verify(signWithKid(t, jwt.SigningMethodHS256, "", claims, []byte("other-secret")))
+	assert.ErrorIs(t, err, ErrTokenSignature)
+}
+
+func TestJWTVerifier_Claims(t *testing.T) {
+	t.Setenv("TEST_JWT_SECRET", testSecret)
+	verifier, err := NewJWTVerifier(config.JWTConfig{
+		Algorithms:       []string{config.JWTAlgorithmHS256},
+		SecretEnv:        "TEST_JWT_SECRET",
+		Issuer:           "https://auth.example.com",
+		Audience:         "salesforge-api",
+		ClockSkewSeconds: 30,
+	})
+	assert.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	verifier.now = func() time.Time { return now }
+
+	standard := jwt.StandardClaims{Issuer: "https://auth.example.com", ExpiresAt: now.Unix() + 60}
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			claims: Claims{Audience: Audience{"salesforge-api"}, StandardClaims: standard},
+		},
+		{
+			name:   "audience list",
+			claims: Claims{Audience: Audience{"other", "salesforge-api"}, StandardClaims: standard},
+		},
+		{
+			name:    "other audience",
+			claims:  Claims{Audience: Audience{"other"}, StandardClaims: standard},
+			wantErr: ErrTokenAudience,
+		},
+		{
+			name:    "missing audience",
+			claims:  Claims{StandardClaims: standard},
+			wantErr: ErrTokenAudience,
+		},
+		{
+			name:    "other issuer",
+			claims:  Claims{Audience: Audience{"salesforge-api"}, StandardClaims: jwt.StandardClaims{Issuer: "https://evil.example.com"}},
+			wantErr: ErrTokenIssuer,
+		},
+		{
+			name:   "expired within skew",
+			claims: Claims{Audience: Audience{"salesforge-api"}, StandardClaims: jwt.StandardClaims{Issuer: standard.Issuer, ExpiresAt: now.Unix() - 20}},
+		},
+		{
+			name:    "expired",
+			claims:  Claims{Audience: Audience{"salesforge-api"}, StandardClaims: jwt.StandardClaims{Issuer: standard.Issuer, ExpiresAt: now.Unix() - 40}},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:   "not before within skew",
+			claims: Claims{Audience: Audience{"salesforge-api"}, StandardClaims: jwt.StandardClaims{Issuer: standard.Issuer, NotBefore: now.Unix() + 20}},
+		},
+		{
+			name:    "not yet valid",
+			claims:  Claims{Audience: Audience{"salesforge-api"}, StandardClaims: jwt.StandardClaims{Issuer: standard.Issuer, NotBefore: now.Unix() + 40}},
+			wantErr: ErrTokenNotYetValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(signedToken(t, &tt.claims))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
+	"testing"
+)
+
+func TestRequirePermission(t *testing.T) {
+	viewer := &auth.Identity{AccountID: 1, UserID: "u-1", Roles: []string{auth.RoleViewer}}
+	readKey := &auth.Identity{AccountID: 1, Roles: []string{auth.RoleAPI}, APIKeyID: 3, Scopes: []string{"read"}}
+
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		method   string
+		wantCode int
+	}{
+		{name: "viewer reads", identity: viewer, method: http.MethodGet, wantCode: http.StatusOK},
+		{name: "viewer deletes step", identity: viewer, method: http.MethodDelete, wantCode: http.StatusForbidden},
+		{name: "read key deletes step", identity: readKey, method: http.MethodDelete, wantCode: http.StatusForbidden},
+		{name: "editor deletes step", identity: &auth.Identity{AccountID: 1, Roles: []string{auth.RoleEditor}}, method: http.MethodDelete, wantCode: http.StatusOK},
+		{name: "unauthenticated", method: http.MethodDelete, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			l := zap.New(core)
+			ok := func(w http.ResponseWriter, r *http.Request) {}
+
+			r := chi.NewRouter()
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tt.identity != nil {
+						r = r.WithContext(auth.NewContext(r.Context(), tt.identity))
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			r.With(RequirePermission(auth.PermissionRead, l)).Get("/v1/sequences/{id}", ok)
+			r.With(RequirePermission(auth.PermissionWrite, l)).Delete("/v1/step", ok)
+
+			path := "/v1/sequences/1"
+			if tt.method == http.MethodDelete {
+				path = "/v1/step"
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, path, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			entries := logs.FilterMessage("permission denied").All()
+			if tt.wantCode != http.StatusForbidden {
+				assert.Empty(t, entries)
+				return
+			}
+
+			var problem sfErr.Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, "/problems/forbidden", problem.Type)
+			assert.Equal(t, "write", problem.RequiredPermission)
+
+			if assert.Len(t, entries, 1) {
+				fields := entries[0].ContextMap()
+				assert.Equal(t, "audit", entries[0].LoggerName)
+				assert.Equal(t, "/v1/step", fields["route"])
+				assert.Equal(t, "write", fields["permission"])
+				assert.Equal(t, tt.identity.AccountID, fields["account_id"])
+				assert.Equal(t, tt.identity.APIKeyID, fields["api_key_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+const (
+	// APIKeyScopeRead allows reading; APIKeyScopeWrite allows every change.
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+)
+
+// APIKey lets a machine client, such as a CRM sync job, act on an account
+// without a JWT. Only the hash of the key is stored; its Prefix is kept in
+// clear so that a key can be told apart in lists and logs.
+type APIKey struct {
+	AccountID  int64    `json:"account_id"`
+	APIKeyID   int64    `json:"api_key_id"`
+	CreatedAt  int64    `json:"created_at"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	LastUsedAt int64    `json:"last_used_at"`
+	RevokedAt  int64    `json:"revoked_at"`
+	KeyHash    string   `json:"-"`
+}
+
+// Revoked reports whether the key can no longer be used.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != 0
+}
+
+func validScopes(scopes []string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, scope := range scopes {
+		if scope != APIKeyScopeRead && scope != APIKeyScopeWrite {
+			return false
+		}
+	}
+	return true
+}
+
+type AddAPIKeyRequest struct {
+	AccountID int64    `json:"account_id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+}
+
+func (aakr *AddAPIKeyRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if aakr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if aakr.Name == "" || len(aakr.Name) > 255 {
+		invalidFields = append(invalidFields, "name")
+		isValid = false
+	}
+
+	if !validScopes(aakr.Scopes) {
+		invalidFields = append(invalidFields, "scopes")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+// AddAPIKeyResponse is the only response carrying the key itself.
+type AddAPIKeyResponse struct {
+	APIKey
+	Key    string `json:"key"`
+	Status string `json:"status"`
+}
+
+type ListAPIKeysRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+func (lakr *ListAPIKeysRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if lakr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
+	Status  string   `json:"status"`
+}
+
+type RevokeAPIKeyRequest struct {
+	AccountID int64 `json:"account_id"`
+	APIKeyID  int64 `json:"api_key_id"`
+}
+
+func (rakr *RevokeAPIKeyRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if rakr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if rakr.APIKeyID <= 0 {
+		invalidFields = append(invalidFields, "api_key_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type RevokeAPIKeyResponse struct {
+	APIKeyID int64  `json:"api_key_id"`
+	Status   string `json:"status"`
+}
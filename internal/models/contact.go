@@ -0,0 +1,230 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezones are validated even where the host has no zoneinfo.
+)
+
+type Contact struct {
+	AccountID    int64             `json:"account_id"`
+	ContactID    int64             `json:"contact_id"`
+	CreatedAt    int64             `json:"created_at"`
+	UpdatedAt    int64             `json:"updated_at"`
+	Email        string            `json:"email"`
+	FirstName    string            `json:"first_name"`
+	LastName     string            `json:"last_name"`
+	Company      string            `json:"company"`
+	Timezone     string            `json:"timezone"`
+	CustomFields map[string]string `json:"custom_fields"`
+}
+
+const DefaultContactTimezone = "UTC"
+
+// NormalizeEmail lowercases and trims an address so that the same contact
+// can't be stored twice under different spellings.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && len(email) <= 320
+}
+
+func validTimezone(timezone string) bool {
+	_, err := time.LoadLocation(timezone)
+	return err == nil && timezone != ""
+}
+
+type AddContactRequest struct {
+	Contact
+}
+
+func (acr *AddContactRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if acr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if !validEmail(acr.Email) {
+		invalidFields = append(invalidFields, "email")
+		isValid = false
+	}
+
+	if !validTimezone(acr.Timezone) {
+		invalidFields = append(invalidFields, "timezone")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type AddContactResponse struct {
+	ContactID int64  `json:"contact_id"`
+	Status    string `json:"status"`
+}
+
+type GetContactRequest struct {
+	AccountID int64 `json:"account_id"`
+	ContactID int64 `json:"contact_id"`
+}
+
+func (gcr *GetContactRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if gcr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if gcr.ContactID <= 0 {
+		invalidFields = append(invalidFields, "contact_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type GetContactResponse struct {
+	Contact
+	Status string `json:"status"`
+}
+
+// ContactCursor is the position of the last contact of a page, handed to
+// clients as an opaque string.
+type ContactCursor struct {
+	ContactID int64 `json:"i"`
+}
+
+func (c *ContactCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeContactCursor(s string) (*ContactCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	cursor := &ContactCursor{}
+	if err := json.Unmarshal(b, cursor); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return cursor, nil
+}
+
+type ListContactsRequest struct {
+	AccountID int64
+	Email     string
+	Limit     int
+	Cursor    *ContactCursor
+}
+
+func (lcr *ListContactsRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if lcr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if lcr.Limit <= 0 || lcr.Limit > MaxListLimit {
+		invalidFields = append(invalidFields, "limit")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type ListContactsResponse struct {
+	Contacts   []Contact `json:"contacts"`
+	NextCursor string    `json:"next_cursor"`
+	Status     string    `json:"status"`
+}
+
+// UpdateContactRequest is a partial update: only the non-nil fields are
+// changed. CustomFields replaces the whole set when sent.
+type UpdateContactRequest struct {
+	AccountID    int64              `json:"account_id"`
+	ContactID    int64              `json:"contact_id"`
+	Email        *string            `json:"email"`
+	FirstName    *string            `json:"first_name"`
+	LastName     *string            `json:"last_name"`
+	Company      *string            `json:"company"`
+	Timezone     *string            `json:"timezone"`
+	CustomFields *map[string]string `json:"custom_fields"`
+}
+
+func (ucr *UpdateContactRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if ucr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if ucr.ContactID <= 0 {
+		invalidFields = append(invalidFields, "contact_id")
+		isValid = false
+	}
+
+	if ucr.Email == nil && ucr.FirstName == nil && ucr.LastName == nil && ucr.Company == nil && ucr.Timezone == nil && ucr.CustomFields == nil {
+		invalidFields = append(invalidFields, "email", "first_name", "last_name", "company", "timezone", "custom_fields")
+		return false, invalidFields
+	}
+
+	if ucr.Email != nil && !validEmail(*ucr.Email) {
+		invalidFields = append(invalidFields, "email")
+		isValid = false
+	}
+
+	if ucr.Timezone != nil && !validTimezone(*ucr.Timezone) {
+		invalidFields = append(invalidFields, "timezone")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type UpdateContactResponse struct {
+	ContactID int64  `json:"contact_id"`
+	Status    string `json:"status"`
+}
+
+type DeleteContactRequest struct {
+	AccountID int64 `json:"account_id"`
+	ContactID int64 `json:"contact_id"`
+}
+
+func (dcr *DeleteContactRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if dcr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if dcr.ContactID <= 0 {
+		invalidFields = append(invalidFields, "contact_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type DeleteContactResponse struct {
+	ContactID int64  `json:"contact_id"`
+	Status    string `json:"status"`
+}
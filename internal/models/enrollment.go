@@ -0,0 +1,138 @@
+package models
+
+const (
+	EnrollmentStatusActive       = "active"
+	EnrollmentStatusPaused       = "paused"
+	EnrollmentStatusFinished     = "finished"
+	EnrollmentStatusReplied      = "replied"
+	EnrollmentStatusBounced      = "bounced"
+	EnrollmentStatusUnsubscribed = "unsubscribed"
+)
+
+// EnrollmentTransitions maps each status a client may set to the status the
+// enrollment must currently have.
+var EnrollmentTransitions = map[string]string{
+	EnrollmentStatusPaused: EnrollmentStatusActive,
+	EnrollmentStatusActive: EnrollmentStatusPaused,
+}
+
+// Enrollment is the progress of one contact through a sequence.
+// CurrentStepID is the next step to send, CurrentStep its position when it
+// was scheduled and NextSendAt when it is due; all are zero once the
+// enrollment has finished. The position only matters when the step is
+// removed, to carry on with the steps that came after it.
+type Enrollment struct {
+	EnrollmentID  int64  `json:"enrollment_id"`
+	AccountID     int64  `json:"account_id"`
+	SequenceID    int64  `json:"sequence_id"`
+	ContactID     int64  `json:"contact_id"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+	Status        string `json:"status"`
+	CurrentStep   int    `json:"current_step"`
+	CurrentStepID int64  `json:"current_step_id"`
+	NextSendAt    int64  `json:"next_send_at"`
+}
+
+type AddEnrollmentsRequest struct {
+	AccountID  int64   `json:"account_id"`
+	SequenceID int64   `json:"sequence_id"`
+	ContactIDs []int64 `json:"contact_ids"`
+}
+
+func (aer *AddEnrollmentsRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if aer.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if aer.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	if len(aer.ContactIDs) == 0 || len(aer.ContactIDs) > MaxListLimit {
+		invalidFields = append(invalidFields, "contact_ids")
+		return false, invalidFields
+	}
+
+	for _, contactId := range aer.ContactIDs {
+		if contactId <= 0 {
+			invalidFields = append(invalidFields, "contact_ids")
+			isValid = false
+			break
+		}
+	}
+
+	return isValid, invalidFields
+}
+
+type AddEnrollmentsResponse struct {
+	SequenceID        int64        `json:"sequence_id"`
+	Enrollments       []Enrollment `json:"enrollments"`
+	SkippedContactIDs []int64      `json:"skipped_contact_ids"`
+	Status            string       `json:"status"`
+}
+
+type UpdateEnrollmentRequest struct {
+	AccountID    int64  `json:"account_id"`
+	EnrollmentID int64  `json:"enrollment_id"`
+	Status       string `json:"status"`
+}
+
+func (uer *UpdateEnrollmentRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if uer.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if uer.EnrollmentID <= 0 {
+		invalidFields = append(invalidFields, "enrollment_id")
+		isValid = false
+	}
+
+	if _, ok := EnrollmentTransitions[uer.Status]; !ok {
+		invalidFields = append(invalidFields, "status")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type UpdateEnrollmentResponse struct {
+	EnrollmentID int64  `json:"enrollment_id"`
+	Status       string `json:"status"`
+}
+
+type DeleteEnrollmentRequest struct {
+	AccountID    int64 `json:"account_id"`
+	EnrollmentID int64 `json:"enrollment_id"`
+}
+
+func (der *DeleteEnrollmentRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if der.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if der.EnrollmentID <= 0 {
+		invalidFields = append(invalidFields, "enrollment_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type DeleteEnrollmentResponse struct {
+	EnrollmentID int64  `json:"enrollment_id"`
+	Status       string `json:"status"`
+}
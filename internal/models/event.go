@@ -0,0 +1,60 @@
+package models
+
+import "strings"
+
+const (
+	EventTypeOpen  = "open"
+	EventTypeClick = "click"
+	EventTypeReply = "reply"
+)
+
+// Event is an open or click of a sent step, recorded from its tracking URL,
+// or a reply to it.
+type Event struct {
+	EventID      int64  `json:"event_id"`
+	AccountID    int64  `json:"account_id"`
+	SequenceID   int64  `json:"sequence_id"`
+	EnrollmentID int64  `json:"enrollment_id"`
+	StepID       int64  `json:"step_id"`
+	Type         string `json:"type"`
+	CreatedAt    int64  `json:"created_at"`
+	UserAgent    string `json:"user_agent"`
+	IPAddress    string `json:"ip_address"`
+	URL          string `json:"url"`
+}
+
+// AddReplyRequest records a reply to a sent step. InReplyTo is the
+// Message-ID the reply answers, from its In-Reply-To header, with or
+// without angle brackets.
+type AddReplyRequest struct {
+	AccountID int64  `json:"account_id"`
+	InReplyTo string `json:"in_reply_to"`
+}
+
+func (arr *AddReplyRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if arr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if arr.MessageID() == "" {
+		invalidFields = append(invalidFields, "in_reply_to")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+// MessageID is InReplyTo without angle brackets, as message ids are stored
+// on sends.
+func (arr *AddReplyRequest) MessageID() string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(arr.InReplyTo), "<"), ">")
+}
+
+type AddReplyResponse struct {
+	EventID int64  `json:"event_id"`
+	Status  string `json:"status"`
+}
@@ -0,0 +1,328 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// MailboxMaxFailures consecutive failed sends make a mailbox unhealthy
+	// until MailboxFailureCooldown has passed since the last one.
+	MailboxMaxFailures     = 3
+	MailboxFailureCooldown = time.Hour
+)
+
+// Mailbox is an address an account sends from. Its daily limit starts at
+// WarmupStartLimit and grows by WarmupDailyIncrease every day until it
+// reaches DailyLimit; a zero WarmupStartLimit disables the warm-up.
+// SentToday, LimitToday and Healthy are computed when the mailbox is read.
+type Mailbox struct {
+	AccountID           int64  `json:"account_id"`
+	MailboxID           int64  `json:"mailbox_id"`
+	CreatedAt           int64  `json:"created_at"`
+	UpdatedAt           int64  `json:"updated_at"`
+	Email               string `json:"email"`
+	FromName            string `json:"from_name"`
+	SmtpHost            string `json:"smtp_host"`
+	SmtpPort            int    `json:"smtp_port"`
+	SmtpUsername        string `json:"smtp_username"`
+	SmtpPassword        string `json:"smtp_password,omitempty"`
+	DailyLimit          int    `json:"daily_limit"`
+	WarmupStartLimit    int    `json:"warmup_start_limit"`
+	WarmupDailyIncrease int    `json:"warmup_daily_increase"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastFailedAt        int64  `json:"last_failed_at"`
+	SentDay             int64  `json:"-"`
+	SentCount           int    `json:"-"`
+	SentToday           int    `json:"sent_today"`
+	LimitToday          int    `json:"limit_today"`
+	Healthy             bool   `json:"healthy"`
+}
+
+// MailboxDay is the start of the UTC day daily limits are counted in.
+func MailboxDay(now time.Time) int64 {
+	return now.Unix() - now.Unix()%int64(24*time.Hour/time.Second)
+}
+
+// Refresh computes the mailbox's usage and health at now.
+func (m *Mailbox) Refresh(now time.Time) {
+	m.SentToday = 0
+	if m.SentDay == MailboxDay(now) {
+		m.SentToday = m.SentCount
+	}
+
+	m.LimitToday = m.DailyLimit
+	if m.WarmupStartLimit > 0 {
+		days := int((now.Unix() - m.CreatedAt) / int64(24*time.Hour/time.Second))
+		if limit := m.WarmupStartLimit + m.WarmupDailyIncrease*days; limit < m.LimitToday {
+			m.LimitToday = limit
+		}
+	}
+
+	m.Healthy = m.ConsecutiveFailures < MailboxMaxFailures || now.Sub(time.Unix(m.LastFailedAt, 0)) >= MailboxFailureCooldown
+}
+
+// Available reports whether the mailbox can send another email today. It
+// expects Refresh to have been called.
+func (m *Mailbox) Available() bool {
+	return m.Healthy && m.SentToday < m.LimitToday
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+type AddMailboxRequest struct {
+	Mailbox
+}
+
+func (amr *AddMailboxRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if amr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if !validEmail(amr.Email) {
+		invalidFields = append(invalidFields, "email")
+		isValid = false
+	}
+
+	if amr.SmtpHost == "" {
+		invalidFields = append(invalidFields, "smtp_host")
+		isValid = false
+	}
+
+	if !validPort(amr.SmtpPort) {
+		invalidFields = append(invalidFields, "smtp_port")
+		isValid = false
+	}
+
+	if amr.DailyLimit <= 0 {
+		invalidFields = append(invalidFields, "daily_limit")
+		isValid = false
+	}
+
+	if amr.WarmupStartLimit < 0 || amr.WarmupStartLimit > amr.DailyLimit {
+		invalidFields = append(invalidFields, "warmup_start_limit")
+		isValid = false
+	}
+
+	if amr.WarmupDailyIncrease < 0 {
+		invalidFields = append(invalidFields, "warmup_daily_increase")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type AddMailboxResponse struct {
+	MailboxID int64  `json:"mailbox_id"`
+	Status    string `json:"status"`
+}
+
+type GetMailboxRequest struct {
+	AccountID int64 `json:"account_id"`
+	MailboxID int64 `json:"mailbox_id"`
+}
+
+func (gmr *GetMailboxRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if gmr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if gmr.MailboxID <= 0 {
+		invalidFields = append(invalidFields, "mailbox_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type GetMailboxResponse struct {
+	Mailbox
+	Status string `json:"status"`
+}
+
+type ListMailboxesRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+func (lmr *ListMailboxesRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if lmr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type ListMailboxesResponse struct {
+	Mailboxes []Mailbox `json:"mailboxes"`
+	Status    string    `json:"status"`
+}
+
+// UpdateMailboxRequest is a partial update: only the non-nil fields are
+// changed. Changing the SMTP settings makes the mailbox healthy again.
+type UpdateMailboxRequest struct {
+	AccountID           int64   `json:"account_id"`
+	MailboxID           int64   `json:"mailbox_id"`
+	FromName            *string `json:"from_name"`
+	SmtpHost            *string `json:"smtp_host"`
+	SmtpPort            *int    `json:"smtp_port"`
+	SmtpUsername        *string `json:"smtp_username"`
+	SmtpPassword        *string `json:"smtp_password"`
+	DailyLimit          *int    `json:"daily_limit"`
+	WarmupStartLimit    *int    `json:"warmup_start_limit"`
+	WarmupDailyIncrease *int    `json:"warmup_daily_increase"`
+}
+
+// SmtpChanged reports whether the update touches the SMTP settings.
+func (umr *UpdateMailboxRequest) SmtpChanged() bool {
+	return umr.SmtpHost != nil || umr.SmtpPort != nil || umr.SmtpUsername != nil || umr.SmtpPassword != nil
+}
+
+func (umr *UpdateMailboxRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if umr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if umr.MailboxID <= 0 {
+		invalidFields = append(invalidFields, "mailbox_id")
+		isValid = false
+	}
+
+	if umr.FromName == nil && !umr.SmtpChanged() && umr.DailyLimit == nil && umr.WarmupStartLimit == nil && umr.WarmupDailyIncrease == nil {
+		invalidFields = append(invalidFields, "from_name", "smtp_host", "smtp_port", "smtp_username", "smtp_password", "daily_limit", "warmup_start_limit", "warmup_daily_increase")
+		return false, invalidFields
+	}
+
+	if umr.SmtpHost != nil && *umr.SmtpHost == "" {
+		invalidFields = append(invalidFields, "smtp_host")
+		isValid = false
+	}
+
+	if umr.SmtpPort != nil && !validPort(*umr.SmtpPort) {
+		invalidFields = append(invalidFields, "smtp_port")
+		isValid = false
+	}
+
+	if umr.DailyLimit != nil && *umr.DailyLimit <= 0 {
+		invalidFields = append(invalidFields, "daily_limit")
+		isValid = false
+	}
+
+	if umr.WarmupStartLimit != nil && *umr.WarmupStartLimit < 0 {
+		invalidFields = append(invalidFields, "warmup_start_limit")
+		isValid = false
+	}
+
+	if umr.WarmupDailyIncrease != nil && *umr.WarmupDailyIncrease < 0 {
+		invalidFields = append(invalidFields, "warmup_daily_increase")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type UpdateMailboxResponse struct {
+	MailboxID int64  `json:"mailbox_id"`
+	Status    string `json:"status"`
+}
+
+type DeleteMailboxRequest struct {
+	AccountID int64 `json:"account_id"`
+	MailboxID int64 `json:"mailbox_id"`
+}
+
+func (dmr *DeleteMailboxRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if dmr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if dmr.MailboxID <= 0 {
+		invalidFields = append(invalidFields, "mailbox_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type DeleteMailboxResponse struct {
+	MailboxID int64  `json:"mailbox_id"`
+	Status    string `json:"status"`
+}
+
+type GetSequenceMailboxesRequest struct {
+	AccountID  int64 `json:"account_id"`
+	SequenceID int64 `json:"sequence_id"`
+}
+
+func (gsmr *GetSequenceMailboxesRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if gsmr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if gsmr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+// SetSequenceMailboxesRequest pins a sequence to a subset of the account's
+// mailboxes. An empty list lets the sequence send from all of them.
+type SetSequenceMailboxesRequest struct {
+	AccountID  int64   `json:"account_id"`
+	SequenceID int64   `json:"sequence_id"`
+	MailboxIDs []int64 `json:"mailbox_ids"`
+}
+
+func (ssmr *SetSequenceMailboxesRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if ssmr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if ssmr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	if ssmr.MailboxIDs == nil || len(ssmr.MailboxIDs) > MaxListLimit {
+		invalidFields = append(invalidFields, "mailbox_ids")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type SequenceMailboxesResponse struct {
+	SequenceID int64   `json:"sequence_id"`
+	MailboxIDs []int64 `json:"mailbox_ids"`
+	Status     string  `json:"status"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMailboxRefresh(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	createdAt := now.Add(-3 * 24 * time.Hour).Unix()
+
+	tests := []struct {
+		name       string
+		mailbox    Mailbox
+		sentToday  int
+		limitToday int
+		healthy    bool
+	}{
+		{
+			name:       "no warm-up",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 50, SentDay: MailboxDay(now), SentCount: 10},
+			sentToday:  10,
+			limitToday: 50,
+			healthy:    true,
+		},
+		{
+			name:       "warming up",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 50, WarmupStartLimit: 10, WarmupDailyIncrease: 5},
+			limitToday: 25,
+			healthy:    true,
+		},
+		{
+			name:       "warmed up",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 20, WarmupStartLimit: 10, WarmupDailyIncrease: 5},
+			limitToday: 20,
+			healthy:    true,
+		},
+		{
+			name:       "sent yesterday",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 50, SentDay: MailboxDay(now) - 86400, SentCount: 50},
+			limitToday: 50,
+			healthy:    true,
+		},
+		{
+			name:       "failing",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 50, ConsecutiveFailures: MailboxMaxFailures, LastFailedAt: now.Add(-time.Minute).Unix()},
+			limitToday: 50,
+			healthy:    false,
+		},
+		{
+			name:       "failing cooled down",
+			mailbox:    Mailbox{CreatedAt: createdAt, DailyLimit: 50, ConsecutiveFailures: MailboxMaxFailures, LastFailedAt: now.Add(-MailboxFailureCooldown).Unix()},
+			limitToday: 50,
+			healthy:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mailbox.Refresh(now)
+			assert.Equal(t, tt.sentToday, tt.mailbox.SentToday)
+			assert.Equal(t, tt.limitToday, tt.mailbox.LimitToday)
+			assert.Equal(t, tt.healthy, tt.mailbox.Healthy)
+		})
+	}
+}
+
+func TestMailboxAvailable(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	mailbox := Mailbox{CreatedAt: now.Unix(), DailyLimit: 2, SentDay: MailboxDay(now), SentCount: 2}
+	mailbox.Refresh(now)
+	assert.False(t, mailbox.Available())
+
+	mailbox.SentCount = 1
+	mailbox.Refresh(now)
+	assert.True(t, mailbox.Available())
+}
@@ -0,0 +1,45 @@
+package models
+
+const (
+	SendStatusSending = "sending"
+	SendStatusSent    = "sent"
+	SendStatusFailed  = "failed"
+)
+
+// Send is the delivery progress of one step to one enrolled contact. A send
+// is sending while it is being delivered. Failed sends are retried until
+// Attempts reaches the scheduler's limit.
+type Send struct {
+	SendID       int64  `json:"send_id"`
+	AccountID    int64  `json:"account_id"`
+	EnrollmentID int64  `json:"enrollment_id"`
+	StepID       int64  `json:"step_id"`
+	MailboxID    int64  `json:"mailbox_id"`
+	VariantID    int64  `json:"variant_id"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	Error        string `json:"error"`
+	MessageID    string `json:"message_id"`
+	SentAt       int64  `json:"sent_at"`
+}
+
+// DueSend is an enrollment whose next step is due, with everything needed to
+// deliver it. Step is the enrollment's current step, or nil when it was
+// removed from the sequence. Send holds earlier attempts at it, and
+// SentStepIDs the steps the contact was already sent.
+// Mailbox is the mailbox to send from; it is nil for accounts without
+// mailboxes, and when all of them are unhealthy or at their daily limit,
+// which MailboxUnavailable tells apart.
+type DueSend struct {
+	Enrollment         Enrollment
+	Sequence           Sequence
+	Steps              []Step
+	Step               *Step
+	Contact            Contact
+	Send               Send
+	SentStepIDs        map[int64]bool
+	Mailbox            *Mailbox
+	MailboxUnavailable bool
+}
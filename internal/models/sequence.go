@@ -1,5 +1,12 @@
 package models
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 type Sequence struct {
 	AccountID                    int64  `json:"account_id"`
 	CreatedAt                    int64  `json:"created_at"`
@@ -20,6 +27,13 @@ type Step struct {
 	WaitDays          int    `json:"wait_days"`
 	EligibleStartTime int64  `json:"eligible_start_time"`
 	EligibleEndTime   int64  `json:"eligible_end_time"`
+	StepOrder         int    `json:"step_order"`
+	// Variants, when there are any, are sent instead of the step's own
+	// subject and body. Once the step was sent AutoPromoteAfter times, the
+	// best performing variant is promoted and sent to everyone after.
+	Variants          []StepVariant `json:"variants"`
+	AutoPromoteAfter  int           `json:"auto_promote_after"`
+	PromotedVariantID int64         `json:"promoted_variant_id"`
 }
 
 type AddSequenceRequest struct {
@@ -41,6 +55,17 @@ func (asr *AddSequenceRequest) Validate() (bool, []string) {
 		isValid = false
 	}
 
+	for i, step := range asr.Steps {
+		if !validVariants(step.Variants) {
+			invalidFields = append(invalidFields, fmt.Sprintf("steps[%d].variants", i))
+			isValid = false
+		}
+		if step.AutoPromoteAfter < 0 {
+			invalidFields = append(invalidFields, fmt.Sprintf("steps[%d].auto_promote_after", i))
+			isValid = false
+		}
+	}
+
 	return isValid, invalidFields
 }
 
@@ -49,11 +74,142 @@ type AddSequenceResponse struct {
 	Status     string `json:"status"`
 }
 
+type GetSequenceRequest struct {
+	AccountID  int64 `json:"account_id"`
+	SequenceID int64 `json:"sequence_id"`
+}
+
+func (gsr *GetSequenceRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if gsr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if gsr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type GetSequenceResponse struct {
+	Sequence
+	Steps  []Step `json:"steps"`
+	Status string `json:"status"`
+}
+
+const (
+	SequenceSortByCreatedAt  = "created_at"
+	SequenceSortBySequenceID = "sequence_id"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// SequenceCursor is the position of the last sequence of a page. It is handed
+// to clients as an opaque string and keeps the sort it was issued for, so a
+// cursor can't be replayed against a different ordering.
+type SequenceCursor struct {
+	SortBy     string `json:"s"`
+	SortOrder  string `json:"o"`
+	CreatedAt  int64  `json:"c"`
+	SequenceID int64  `json:"i"`
+}
+
+func (c *SequenceCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeSequenceCursor(s string) (*SequenceCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	cursor := &SequenceCursor{}
+	if err := json.Unmarshal(b, cursor); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return cursor, nil
+}
+
+type ListSequencesRequest struct {
+	AccountID            int64
+	Name                 string
+	OpenTrackingEnabled  *bool
+	ClickTrackingEnabled *bool
+	CreatedFrom          int64
+	CreatedTo            int64
+	UpdatedFrom          int64
+	UpdatedTo            int64
+	SortBy               string
+	SortOrder            string
+	Limit                int
+	Cursor               *SequenceCursor
+}
+
+func (lsr *ListSequencesRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if lsr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if lsr.SortBy != SequenceSortByCreatedAt && lsr.SortBy != SequenceSortBySequenceID {
+		invalidFields = append(invalidFields, "sort_by")
+		isValid = false
+	}
+
+	if lsr.SortOrder != SortOrderAsc && lsr.SortOrder != SortOrderDesc {
+		invalidFields = append(invalidFields, "sort_order")
+		isValid = false
+	}
+
+	if lsr.Limit <= 0 || lsr.Limit > MaxListLimit {
+		invalidFields = append(invalidFields, "limit")
+		isValid = false
+	}
+
+	if lsr.CreatedFrom < 0 || lsr.CreatedTo < 0 || (lsr.CreatedTo > 0 && lsr.CreatedFrom > lsr.CreatedTo) {
+		invalidFields = append(invalidFields, "created_from")
+		isValid = false
+	}
+
+	if lsr.UpdatedFrom < 0 || lsr.UpdatedTo < 0 || (lsr.UpdatedTo > 0 && lsr.UpdatedFrom > lsr.UpdatedTo) {
+		invalidFields = append(invalidFields, "updated_from")
+		isValid = false
+	}
+
+	if lsr.Cursor != nil && (lsr.Cursor.SortBy != lsr.SortBy || lsr.Cursor.SortOrder != lsr.SortOrder) {
+		invalidFields = append(invalidFields, "cursor")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type ListSequencesResponse struct {
+	Sequences  []Sequence `json:"sequences"`
+	NextCursor string     `json:"next_cursor"`
+	Status     string     `json:"status"`
+}
+
+// UpdateSequenceRequest is a partial update: only the non-nil fields are changed.
 type UpdateSequenceRequest struct {
-	AccountID                    int64 `json:"account_id"`
-	SequenceID                   int64 `json:"sequence_id"`
-	SequenceOpenTrackingEnabled  *bool `json:"sequence_open_tracking_enabled"`
-	SequenceClickTrackingEnabled *bool `json:"sequence_click_tracking_enabled"`
+	AccountID                    int64   `json:"account_id"`
+	SequenceID                   int64   `json:"sequence_id"`
+	SequenceName                 *string `json:"sequence_name"`
+	SequenceOpenTrackingEnabled  *bool   `json:"sequence_open_tracking_enabled"`
+	SequenceClickTrackingEnabled *bool   `json:"sequence_click_tracking_enabled"`
 }
 
 func (usr *UpdateSequenceRequest) Validate() (bool, []string) {
@@ -70,13 +226,13 @@ func (usr *UpdateSequenceRequest) Validate() (bool, []string) {
 		isValid = false
 	}
 
-	if usr.SequenceOpenTrackingEnabled == nil {
-		invalidFields = append(invalidFields, "sequence_open_tracking_enabled")
-		isValid = false
+	if usr.SequenceName == nil && usr.SequenceOpenTrackingEnabled == nil && usr.SequenceClickTrackingEnabled == nil {
+		invalidFields = append(invalidFields, "sequence_name", "sequence_open_tracking_enabled", "sequence_click_tracking_enabled")
+		return false, invalidFields
 	}
 
-	if usr.SequenceClickTrackingEnabled == nil {
-		invalidFields = append(invalidFields, "sequence_click_tracking_enabled")
+	if usr.SequenceName != nil && (*usr.SequenceName == "" || len(*usr.SequenceName) > 255) {
+		invalidFields = append(invalidFields, "sequence_name")
 		isValid = false
 	}
 
@@ -88,12 +244,214 @@ type UpdateSequenceResponse struct {
 	Status     string `json:"status"`
 }
 
+type CloneSequenceRequest struct {
+	AccountID       int64   `json:"account_id"`
+	SequenceID      int64   `json:"sequence_id"`
+	TargetAccountID int64   `json:"target_account_id"`
+	SequenceName    *string `json:"sequence_name"`
+}
+
+func (csr *CloneSequenceRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if csr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if csr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	// Zero clones into the source account.
+	if csr.TargetAccountID < 0 {
+		invalidFields = append(invalidFields, "target_account_id")
+		isValid = false
+	}
+
+	if csr.SequenceName != nil && (*csr.SequenceName == "" || len(*csr.SequenceName) > 255) {
+		invalidFields = append(invalidFields, "sequence_name")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type CloneSequenceResponse struct {
+	AccountID  int64  `json:"account_id"`
+	SequenceID int64  `json:"sequence_id"`
+	Status     string `json:"status"`
+}
+
+type DeleteSequenceRequest struct {
+	AccountID  int64 `json:"account_id"`
+	SequenceID int64 `json:"sequence_id"`
+	Archive    bool  `json:"archive"`
+}
+
+func (dsr *DeleteSequenceRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if dsr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if dsr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type DeleteSequenceResponse struct {
+	SequenceID int64  `json:"sequence_id"`
+	Status     string `json:"status"`
+}
+
+type AddStepRequest struct {
+	AccountID         int64         `json:"account_id"`
+	SequenceID        int64         `json:"sequence_id"`
+	Position          int           `json:"position"`
+	StepEmailSubject  string        `json:"step_email_subject"`
+	StepEmailBody     string        `json:"step_email_body"`
+	WaitDays          int           `json:"wait_days"`
+	EligibleStartTime int64         `json:"eligible_start_time"`
+	EligibleEndTime   int64         `json:"eligible_end_time"`
+	Variants          []StepVariant `json:"variants"`
+	AutoPromoteAfter  int           `json:"auto_promote_after"`
+}
+
+func (asr *AddStepRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if asr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if asr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	// Position is 1-based; zero appends the step after the last one.
+	if asr.Position < 0 {
+		invalidFields = append(invalidFields, "position")
+		isValid = false
+	}
+
+	if asr.StepEmailSubject == "" {
+		invalidFields = append(invalidFields, "step_email_subject")
+		isValid = false
+	}
+
+	if asr.StepEmailBody == "" {
+		invalidFields = append(invalidFields, "step_email_body")
+		isValid = false
+	}
+
+	if asr.WaitDays < 0 {
+		invalidFields = append(invalidFields, "wait_days")
+		isValid = false
+	}
+
+	if asr.EligibleStartTime >= asr.EligibleEndTime {
+		invalidFields = append(invalidFields, "eligible_start_time")
+		isValid = false
+	}
+
+	if !validVariants(asr.Variants) {
+		invalidFields = append(invalidFields, "variants")
+		isValid = false
+	}
+
+	if asr.AutoPromoteAfter < 0 {
+		invalidFields = append(invalidFields, "auto_promote_after")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+func (asr *AddStepRequest) ToStep() Step {
+	return Step{
+		SequenceID:        asr.SequenceID,
+		StepEmailSubject:  asr.StepEmailSubject,
+		StepEmailBody:     asr.StepEmailBody,
+		WaitDays:          asr.WaitDays,
+		EligibleStartTime: asr.EligibleStartTime,
+		EligibleEndTime:   asr.EligibleEndTime,
+		Variants:          asr.Variants,
+		AutoPromoteAfter:  asr.AutoPromoteAfter,
+	}
+}
+
+type AddStepResponse struct {
+	SequenceID int64  `json:"sequence_id"`
+	StepID     int64  `json:"step_id"`
+	StepOrder  int    `json:"step_order"`
+	Status     string `json:"status"`
+}
+
+type ReorderStepsRequest struct {
+	AccountID  int64   `json:"account_id"`
+	SequenceID int64   `json:"sequence_id"`
+	StepIDs    []int64 `json:"step_ids"`
+}
+
+func (rsr *ReorderStepsRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if rsr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if rsr.SequenceID <= 0 {
+		invalidFields = append(invalidFields, "sequence_id")
+		isValid = false
+	}
+
+	seen := make(map[int64]bool, len(rsr.StepIDs))
+	for _, stepId := range rsr.StepIDs {
+		if stepId <= 0 || seen[stepId] {
+			invalidFields = append(invalidFields, "step_ids")
+			isValid = false
+			break
+		}
+		seen[stepId] = true
+	}
+
+	return isValid, invalidFields
+}
+
+type ReorderStepsResponse struct {
+	SequenceID int64   `json:"sequence_id"`
+	StepIDs    []int64 `json:"step_ids"`
+	Status     string  `json:"status"`
+}
+
+// UpdateStepRequest is a partial update: only the non-nil fields are changed.
+// Variants replaces the whole set when sent; variants sent with their
+// VariantID keep it, and their stats.
 type UpdateStepRequest struct {
-	AccountID        int64  `json:"account_id"`
-	StepID           int64  `json:"step_id"`
-	SequenceID       int64  `json:"sequence_id"`
-	StepEmailSubject string `json:"step_email_subject"`
-	StepEmailBody    string `json:"step_email_body"`
+	AccountID         int64          `json:"account_id"`
+	StepID            int64          `json:"step_id"`
+	SequenceID        int64          `json:"sequence_id"`
+	StepEmailSubject  *string        `json:"step_email_subject"`
+	StepEmailBody     *string        `json:"step_email_body"`
+	WaitDays          *int           `json:"wait_days"`
+	EligibleStartTime *int64         `json:"eligible_start_time"`
+	EligibleEndTime   *int64         `json:"eligible_end_time"`
+	Variants          *[]StepVariant `json:"variants"`
+	AutoPromoteAfter  *int           `json:"auto_promote_after"`
 }
 
 func (usr *UpdateStepRequest) Validate() (bool, []string) {
@@ -115,16 +473,43 @@ func (usr *UpdateStepRequest) Validate() (bool, []string) {
 		isValid = false
 	}
 
-	if usr.StepEmailSubject == "" {
+	if usr.StepEmailSubject == nil && usr.StepEmailBody == nil && usr.WaitDays == nil && usr.EligibleStartTime == nil && usr.EligibleEndTime == nil && usr.Variants == nil && usr.AutoPromoteAfter == nil {
+		invalidFields = append(invalidFields, "step_email_subject", "step_email_body", "wait_days", "eligible_start_time", "eligible_end_time", "variants", "auto_promote_after")
+		return false, invalidFields
+	}
+
+	if usr.StepEmailSubject != nil && *usr.StepEmailSubject == "" {
 		invalidFields = append(invalidFields, "step_email_subject")
 		isValid = false
 	}
 
-	if usr.StepEmailBody == "" {
+	if usr.StepEmailBody != nil && *usr.StepEmailBody == "" {
 		invalidFields = append(invalidFields, "step_email_body")
 		isValid = false
 	}
 
+	if usr.WaitDays != nil && *usr.WaitDays < 0 {
+		invalidFields = append(invalidFields, "wait_days")
+		isValid = false
+	}
+
+	// When only one end of the window is sent, it is checked against the
+	// stored value by the repository.
+	if usr.EligibleStartTime != nil && usr.EligibleEndTime != nil && *usr.EligibleStartTime >= *usr.EligibleEndTime {
+		invalidFields = append(invalidFields, "eligible_start_time")
+		isValid = false
+	}
+
+	if usr.Variants != nil && !validVariants(*usr.Variants) {
+		invalidFields = append(invalidFields, "variants")
+		isValid = false
+	}
+
+	if usr.AutoPromoteAfter != nil && *usr.AutoPromoteAfter < 0 {
+		invalidFields = append(invalidFields, "auto_promote_after")
+		isValid = false
+	}
+
 	return isValid, invalidFields
 }
 
@@ -167,3 +552,54 @@ type DeleteStepResponse struct {
 	StepID     int64  `json:"step_id"`
 	Status     string `json:"status"`
 }
+
+// PreviewStepRequest renders a step for the stored contact ContactID, or for
+// the unsaved Contact. With neither, every variable is missing.
+type PreviewStepRequest struct {
+	AccountID int64    `json:"account_id"`
+	StepID    int64    `json:"step_id"`
+	ContactID int64    `json:"contact_id"`
+	Contact   *Contact `json:"contact"`
+}
+
+func (psr *PreviewStepRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if psr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if psr.StepID <= 0 {
+		invalidFields = append(invalidFields, "step_id")
+		isValid = false
+	}
+
+	if psr.ContactID < 0 {
+		invalidFields = append(invalidFields, "contact_id")
+		isValid = false
+	}
+
+	if psr.ContactID != 0 && psr.Contact != nil {
+		invalidFields = append(invalidFields, "contact_id", "contact")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+// PreviewStepResponse is the step as the contact would receive it, with the
+// variant it would get, if the step has any. SendAt is when the step would
+// go out if the previous one was sent now, or zero when its eligible window
+// closes before then.
+type PreviewStepResponse struct {
+	StepID           int64    `json:"step_id"`
+	VariantID        int64    `json:"variant_id"`
+	Subject          string   `json:"subject"`
+	HTMLBody         string   `json:"html_body"`
+	TextBody         string   `json:"text_body"`
+	MissingVariables []string `json:"missing_variables"`
+	SendAt           int64    `json:"send_at"`
+	Status           string   `json:"status"`
+}
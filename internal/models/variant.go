@@ -0,0 +1,165 @@
+package models
+
+import (
+	"hash/fnv"
+	"strconv"
+)
+
+// MaxStepVariants is the most variants a step can be A/B tested with.
+const MaxStepVariants = 10
+
+// StepVariant is an alternative subject and body for a step. Each recipient
+// gets one variant, with a chance proportional to its Weight; a zero weight
+// pauses the variant.
+type StepVariant struct {
+	VariantID        int64  `json:"variant_id"`
+	StepEmailSubject string `json:"step_email_subject"`
+	StepEmailBody    string `json:"step_email_body"`
+	Weight           int    `json:"weight"`
+}
+
+func validVariants(variants []StepVariant) bool {
+	if len(variants) > MaxStepVariants {
+		return false
+	}
+	totalWeight := 0
+	for _, variant := range variants {
+		if variant.StepEmailSubject == "" || variant.StepEmailBody == "" || variant.Weight < 0 {
+			return false
+		}
+		totalWeight += variant.Weight
+	}
+	return len(variants) == 0 || totalWeight > 0
+}
+
+// PickVariant is the variant recipient gets: the promoted one once a winner
+// was chosen, otherwise one picked by weight from a hash of the recipient
+// and step, so the same contact always gets the same variant. It is nil for
+// steps without variants.
+func (s *Step) PickVariant(recipient string) *StepVariant {
+	if len(s.Variants) == 0 {
+		return nil
+	}
+	totalWeight := 0
+	for i := range s.Variants {
+		if s.Variants[i].VariantID == s.PromotedVariantID && s.PromotedVariantID != 0 {
+			return &s.Variants[i]
+		}
+		totalWeight += s.Variants[i].Weight
+	}
+	if totalWeight <= 0 {
+		return &s.Variants[0]
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(NormalizeEmail(recipient) + ":" + strconv.FormatInt(s.StepID, 10)))
+	point := int(h.Sum64() % uint64(totalWeight))
+	for i := range s.Variants {
+		if point < s.Variants[i].Weight {
+			return &s.Variants[i]
+		}
+		point -= s.Variants[i].Weight
+	}
+	return &s.Variants[len(s.Variants)-1]
+}
+
+// Variant is the step's variant with variantId, or nil when it has none.
+func (s *Step) Variant(variantId int64) *StepVariant {
+	for i := range s.Variants {
+		if s.Variants[i].VariantID == variantId && variantId != 0 {
+			return &s.Variants[i]
+		}
+	}
+	return nil
+}
+
+// VariantContent is the subject and body of variant, or of the step itself
+// when variant is nil.
+func (s *Step) VariantContent(variant *StepVariant) (subject string, body string) {
+	if variant != nil {
+		return variant.StepEmailSubject, variant.StepEmailBody
+	}
+	return s.StepEmailSubject, s.StepEmailBody
+}
+
+// Content is the subject and body recipient gets, from its variant or the
+// step itself. variantId is zero when the step has no variants.
+func (s *Step) Content(recipient string) (subject string, body string, variantId int64) {
+	variant := s.PickVariant(recipient)
+	subject, body = s.VariantContent(variant)
+	if variant != nil {
+		variantId = variant.VariantID
+	}
+	return subject, body, variantId
+}
+
+// VariantStats is how a variant performed. Opens, clicks and replies count
+// each recipient once.
+type VariantStats struct {
+	VariantID        int64  `json:"variant_id"`
+	StepEmailSubject string `json:"step_email_subject"`
+	Weight           int    `json:"weight"`
+	Sends            int    `json:"sends"`
+	Opens            int    `json:"opens"`
+	Clicks           int    `json:"clicks"`
+	Replies          int    `json:"replies"`
+}
+
+// better reports whether a outperformed b, comparing the reply, click and
+// open rates in that order.
+func (a VariantStats) better(b VariantStats) bool {
+	rates := [][2]int{{a.Replies, b.Replies}, {a.Clicks, b.Clicks}, {a.Opens, b.Opens}}
+	for _, r := range rates {
+		// a/aSends vs b/bSends, cross-multiplied to stay in integers.
+		x, y := r[0]*b.Sends, r[1]*a.Sends
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// Winner is the best performing variant that was sent at least once, or nil
+// when none was. Ties go to the earlier variant.
+func Winner(stats []VariantStats) *VariantStats {
+	var winner *VariantStats
+	for i := range stats {
+		if stats[i].Sends == 0 {
+			continue
+		}
+		if winner == nil || stats[i].better(*winner) {
+			winner = &stats[i]
+		}
+	}
+	return winner
+}
+
+type GetVariantStatsRequest struct {
+	AccountID int64 `json:"account_id"`
+	StepID    int64 `json:"step_id"`
+}
+
+func (gvr *GetVariantStatsRequest) Validate() (bool, []string) {
+	var invalidFields []string
+	var isValid bool = true
+
+	if gvr.AccountID <= 0 {
+		invalidFields = append(invalidFields, "account_id")
+		isValid = false
+	}
+
+	if gvr.StepID <= 0 {
+		invalidFields = append(invalidFields, "step_id")
+		isValid = false
+	}
+
+	return isValid, invalidFields
+}
+
+type GetVariantStatsResponse struct {
+	StepID            int64          `json:"step_id"`
+	AutoPromoteAfter  int            `json:"auto_promote_after"`
+	PromotedVariantID int64          `json:"promoted_variant_id"`
+	Variants          []VariantStats `json:"variants"`
+	Status            string         `json:"status"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPickVariant(t *testing.T) {
+	step := Step{
+		StepID: 7,
+		Variants: []StepVariant{
+			{VariantID: 1, StepEmailSubject: "A", Weight: 3},
+			{VariantID: 2, StepEmailSubject: "B", Weight: 1},
+			{VariantID: 3, StepEmailSubject: "C", Weight: 0},
+		},
+	}
+
+	counts := map[int64]int{}
+	for i := 0; i < 4000; i++ {
+		recipient := fmt.Sprintf("contact%d@example.com", i)
+		variant := step.PickVariant(recipient)
+		counts[variant.VariantID]++
+
+		// The same recipient always gets the same variant, however the
+		// address is spelled.
+		assert.Equal(t, variant, step.PickVariant(" CONTACT"+recipient[7:]))
+	}
+	assert.InDelta(t, 3000, counts[1], 150)
+	assert.InDelta(t, 1000, counts[2], 150)
+	assert.Zero(t, counts[3])
+
+	step.PromotedVariantID = 2
+	assert.Equal(t, int64(2), step.PickVariant("contact1@example.com").VariantID)
+}
+
+func TestStepContent(t *testing.T) {
+	step := Step{StepID: 1, StepEmailSubject: "Subject", StepEmailBody: "Body"}
+	subject, body, variantId := step.Content("jane.doe@example.com")
+	assert.Equal(t, "Subject", subject)
+	assert.Equal(t, "Body", body)
+	assert.Zero(t, variantId)
+
+	step.Variants = []StepVariant{{VariantID: 5, StepEmailSubject: "Variant", StepEmailBody: "Variant body", Weight: 1}}
+	subject, body, variantId = step.Content("jane.doe@example.com")
+	assert.Equal(t, "Variant", subject)
+	assert.Equal(t, "Variant body", body)
+	assert.Equal(t, int64(5), variantId)
+}
+
+func TestStepVariant(t *testing.T) {
+	step := Step{Variants: []StepVariant{{VariantID: 5}, {VariantID: 6}}}
+	assert.Equal(t, int64(6), step.Variant(6).VariantID)
+	assert.Nil(t, step.Variant(7))
+	assert.Nil(t, step.Variant(0))
+}
+
+func TestWinner(t *testing.T) {
+	stats := []VariantStats{
+		{VariantID: 1, Sends: 100, Opens: 50, Clicks: 10, Replies: 2},
+		{VariantID: 2, Sends: 50, Opens: 10, Clicks: 2, Replies: 2},
+		{VariantID: 3, Sends: 0},
+	}
+	// Variant 2 replied at twice the rate.
+	assert.Equal(t, int64(2), Winner(stats).VariantID)
+
+	// Equal reply rates fall back to clicks, then opens.
+	stats[1].Replies = 1
+	assert.Equal(t, int64(1), Winner(stats).VariantID)
+	stats[1].Clicks = 5
+	stats[1].Opens = 30
+	assert.Equal(t, int64(2), Winner(stats).VariantID)
+	stats[1].Opens = 25
+	assert.Equal(t, int64(1), Winner(stats).VariantID)
+
+	assert.Nil(t, Winner([]VariantStats{{VariantID: 1}}))
+}
+
+func TestAddStepRequestValidate_Variants(t *testing.T) {
+	add := AddStepRequest{
+		AccountID:        1,
+		SequenceID:       1,
+		StepEmailSubject: "Subject",
+		StepEmailBody:    "Body",
+		EligibleEndTime:  1,
+		Variants: []StepVariant{
+			{StepEmailSubject: "A", StepEmailBody: "Body A", Weight: 0},
+			{StepEmailSubject: "B", StepEmailBody: "Body B", Weight: 0},
+		},
+	}
+	isValid, invalidFields := add.Validate()
+	assert.False(t, isValid)
+	assert.Equal(t, []string{"variants"}, invalidFields)
+
+	add.Variants[1].Weight = 1
+	isValid, _ = add.Validate()
+	assert.True(t, isValid)
+
+	add.Variants[0].StepEmailBody = ""
+	add.AutoPromoteAfter = -1
+	isValid, invalidFields = add.Validate()
+	assert.False(t, isValid)
+	assert.Equal(t, []string{"variants", "auto_promote_after"}, invalidFields)
+}
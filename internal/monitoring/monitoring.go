@@ -22,10 +22,17 @@ var (
 		},
 		[]string{"path"},
 	)
+	authRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_rejections_total",
+			Help: "Total number of requests rejected by authentication, by reason",
+		},
+		[]string{"reason"},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration)
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, authRejectionsTotal)
 }
 
 func MetricsHandler() http.Handler {
@@ -36,3 +43,7 @@ func RecordMetrics(path string, duration float64) {
 	httpRequestsTotal.WithLabelValues(path).Inc()
 	httpRequestDuration.WithLabelValues(path).Observe(duration)
 }
+
+func RecordAuthRejection(reason string) {
+	authRejectionsTotal.WithLabelValues(reason).Inc()
+}
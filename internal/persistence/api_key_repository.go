@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"salesforge-api/internal/models"
+	"time"
+)
+
+// apiKeyTouchInterval is how stale last_used_at may get, so that every
+// request made with a key doesn't write to it.
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyRepository interface {
+	AddAPIKey(ctx context.Context, apiKey *models.APIKey) (apiKeyId int64, err error)
+	ListAPIKeys(ctx context.Context, list *models.ListAPIKeysRequest) (apiKeys []models.APIKey, err error)
+	RevokeAPIKey(ctx context.Context, revoke *models.RevokeAPIKeyRequest) (apiKeyId int64, err error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (apiKey *models.APIKey, err error)
+	TouchAPIKey(ctx context.Context, apiKeyId int64, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `account_id, api_key_id, created_at, name, prefix, key_hash, scopes, last_used_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	var lastUsedAt sql.NullInt64
+	var revokedAt sql.NullInt64
+	err := row.Scan(&apiKey.AccountID, &apiKey.APIKeyID, &apiKey.CreatedAt, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, pq.Array(&apiKey.Scopes), &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.LastUsedAt = lastUsedAt.Int64
+	apiKey.RevokedAt = revokedAt.Int64
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) AddAPIKey(ctx context.Context, apiKey *models.APIKey) (apiKeyId int64, err error) {
+	query := `INSERT INTO api_keys (account_id, created_at, name, prefix, key_hash, scopes) VALUES ($1, $2, $3, $4, $5, $6) RETURNING api_key_id`
+	err = r.db.QueryRowContext(ctx, query, apiKey.AccountID, apiKey.CreatedAt, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, pq.Array(apiKey.Scopes)).Scan(&apiKeyId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return apiKeyId, nil
+}
+
+// ListAPIKeys returns revoked keys too, but never the key hashes.
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, list *models.ListAPIKeysRequest) (apiKeys []models.APIKey, err error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE account_id = $1 ORDER BY api_key_id`
+	rows, err := r.db.QueryContext(ctx, query, list.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys = []models.APIKey{}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.KeyHash = ""
+		apiKeys = append(apiKeys, *apiKey)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// RevokeAPIKey keeps the time a key was first revoked, so revoking it again
+// succeeds without changing it.
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, revoke *models.RevokeAPIKeyRequest) (apiKeyId int64, err error) {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE account_id = $2 AND api_key_id = $3 RETURNING api_key_id`
+	err = r.db.QueryRowContext(ctx, query, time.Now().Unix(), revoke.AccountID, revoke.APIKeyID).Scan(&apiKeyId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return apiKeyId, nil
+}
+
+// GetAPIKeyByPrefix looks up the key a client presented, hash included.
+func (r *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (apiKey *models.APIKey, err error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	apiKey, err = scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return apiKey, nil
+}
+
+// TouchAPIKey records when the key was last used, at most once every
+// apiKeyTouchInterval.
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, apiKeyId int64, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE api_key_id = $2 AND (last_used_at IS NULL OR last_used_at <= $3)`
+	_, err := r.db.ExecContext(ctx, query, usedAt.Unix(), apiKeyId, usedAt.Add(-apiKeyTouchInterval).Unix())
+	return err
+}
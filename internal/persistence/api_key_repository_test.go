@@ -0,0 +1,74 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestAPIKeys_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewAPIKeyRepository(db)
+
+	ctx := context.Background()
+	apiKey := models.APIKey{
+		AccountID: 1,
+		CreatedAt: time.Now().Unix(),
+		Name:      "CRM sync",
+		Prefix:    "sf_0123456789ab",
+		Scopes:    []string{models.APIKeyScopeRead, models.APIKeyScopeWrite},
+		KeyHash:   "hash",
+	}
+	apiKeyId, err := repo.AddAPIKey(ctx, &apiKey)
+	if err != nil {
+		t.Fatalf("failed to add api key: %v", err)
+	}
+
+	// Prefixes identify a single key
+	if _, err := repo.AddAPIKey(ctx, &apiKey); !errors.Is(err, persistence.ErrConflict) {
+		t.Fatalf("expected ErrConflict adding a duplicate prefix, got %v", err)
+	}
+
+	stored, err := repo.GetAPIKeyByPrefix(ctx, apiKey.Prefix)
+	if err != nil {
+		t.Fatalf("failed to get api key: %v", err)
+	}
+	if stored.APIKeyID != apiKeyId || stored.KeyHash != "hash" || len(stored.Scopes) != 2 || stored.LastUsedAt != 0 {
+		t.Fatalf("unexpected api key %+v", stored)
+	}
+
+	// Last use is only recorded once a minute
+	usedAt := time.Now()
+	if err := repo.TouchAPIKey(ctx, apiKeyId, usedAt); err != nil {
+		t.Fatalf("failed to touch api key: %v", err)
+	}
+	if err := repo.TouchAPIKey(ctx, apiKeyId, usedAt.Add(30*time.Second)); err != nil {
+		t.Fatalf("failed to touch api key: %v", err)
+	}
+	stored, err = repo.GetAPIKeyByPrefix(ctx, apiKey.Prefix)
+	if err != nil || stored.LastUsedAt != usedAt.Unix() {
+		t.Fatalf("expected last use at %d, got %+v and %v", usedAt.Unix(), stored, err)
+	}
+
+	// Keys of another account can't be revoked
+	if _, err := repo.RevokeAPIKey(ctx, &models.RevokeAPIKeyRequest{AccountID: 2, APIKeyID: apiKeyId}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound revoking another account's key, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := repo.RevokeAPIKey(ctx, &models.RevokeAPIKeyRequest{AccountID: 1, APIKeyID: apiKeyId}); err != nil {
+			t.Fatalf("failed to revoke api key: %v", err)
+		}
+	}
+
+	apiKeys, err := repo.ListAPIKeys(ctx, &models.ListAPIKeysRequest{AccountID: 1})
+	if err != nil {
+		t.Fatalf("failed to list api keys: %v", err)
+	}
+	if len(apiKeys) != 1 || !apiKeys[0].Revoked() || apiKeys[0].KeyHash != "" {
+		t.Fatalf("unexpected api keys %+v", apiKeys)
+	}
+}
@@ -0,0 +1,173 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"salesforge-api/internal/models"
+	"strings"
+	"time"
+)
+
+type ContactRepository interface {
+	AddContact(ctx context.Context, contact *models.Contact) (contactId int64, err error)
+	GetContact(ctx context.Context, get *models.GetContactRequest) (contact *models.Contact, err error)
+	ListContacts(ctx context.Context, list *models.ListContactsRequest) (contacts []models.Contact, next *models.ContactCursor, err error)
+	UpdateContact(ctx context.Context, update *models.UpdateContactRequest) (contactId int64, err error)
+	DeleteContact(ctx context.Context, delete *models.DeleteContactRequest) (contactId int64, err error)
+}
+
+type contactRepository struct {
+	db *sql.DB
+}
+
+func NewContactRepository(db *sql.DB) ContactRepository {
+	return &contactRepository{
+		db: db,
+	}
+}
+
+const contactColumns = `account_id, contact_id, created_at, updated_at, email, first_name, last_name, company, timezone, custom_fields`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanContact(row rowScanner) (*models.Contact, error) {
+	var contact models.Contact
+	var updatedAt sql.NullInt64
+	var customFields []byte
+	err := row.Scan(&contact.AccountID, &contact.ContactID, &contact.CreatedAt, &updatedAt, &contact.Email, &contact.FirstName, &contact.LastName, &contact.Company, &contact.Timezone, &customFields)
+	if err != nil {
+		return nil, err
+	}
+	contact.UpdatedAt = updatedAt.Int64
+	if err := json.Unmarshal(customFields, &contact.CustomFields); err != nil {
+		return nil, err
+	}
+
+	return &contact, nil
+}
+
+func marshalCustomFields(customFields map[string]string) ([]byte, error) {
+	if customFields == nil {
+		customFields = map[string]string{}
+	}
+	return json.Marshal(customFields)
+}
+
+func (r *contactRepository) AddContact(ctx context.Context, contact *models.Contact) (contactId int64, err error) {
+	customFields, err := marshalCustomFields(contact.CustomFields)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO contacts (account_id, created_at, email, first_name, last_name, company, timezone, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING contact_id`
+	createdAt := time.Now().Unix()
+	err = r.db.QueryRowContext(ctx, query, contact.AccountID, createdAt, contact.Email, contact.FirstName, contact.LastName, contact.Company, contact.Timezone, customFields).Scan(&contactId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return contactId, nil
+}
+
+func (r *contactRepository) GetContact(ctx context.Context, get *models.GetContactRequest) (contact *models.Contact, err error) {
+	query := `SELECT ` + contactColumns + ` FROM contacts WHERE account_id = $1 AND contact_id = $2`
+	contact, err = scanContact(r.db.QueryRowContext(ctx, query, get.AccountID, get.ContactID))
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return contact, nil
+}
+
+func (r *contactRepository) ListContacts(ctx context.Context, list *models.ListContactsRequest) (contacts []models.Contact, next *models.ContactCursor, err error) {
+	conditions := []string{"account_id = $1"}
+	args := []interface{}{list.AccountID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if list.Email != "" {
+		conditions = append(conditions, "email LIKE '%' || "+arg(likeEscaper.Replace(models.NormalizeEmail(list.Email)))+" || '%'")
+	}
+	if list.Cursor != nil {
+		conditions = append(conditions, "contact_id > "+arg(list.Cursor.ContactID))
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	query := `SELECT ` + contactColumns + ` FROM contacts WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY contact_id LIMIT ` + arg(list.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+	defer rows.Close()
+
+	contacts = []models.Contact{}
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, nil, translateError(err)
+		}
+		contacts = append(contacts, *contact)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	if len(contacts) > list.Limit {
+		contacts = contacts[:list.Limit]
+		next = &models.ContactCursor{ContactID: contacts[len(contacts)-1].ContactID}
+	}
+
+	return contacts, next, nil
+}
+
+func (r *contactRepository) UpdateContact(ctx context.Context, update *models.UpdateContactRequest) (contactId int64, err error) {
+	u := &updateBuilder{}
+	if update.Email != nil {
+		u.set("email", *update.Email)
+	}
+	if update.FirstName != nil {
+		u.set("first_name", *update.FirstName)
+	}
+	if update.LastName != nil {
+		u.set("last_name", *update.LastName)
+	}
+	if update.Company != nil {
+		u.set("company", *update.Company)
+	}
+	if update.Timezone != nil {
+		u.set("timezone", *update.Timezone)
+	}
+	if update.CustomFields != nil {
+		customFields, err := marshalCustomFields(*update.CustomFields)
+		if err != nil {
+			return 0, err
+		}
+		u.set("custom_fields", customFields)
+	}
+	u.set("updated_at", time.Now().Unix())
+
+	query := `UPDATE contacts SET ` + u.clause() + ` WHERE account_id = ` + u.arg(update.AccountID) + ` AND contact_id = ` + u.arg(update.ContactID) + ` RETURNING contact_id`
+	err = r.db.QueryRowContext(ctx, query, u.args...).Scan(&contactId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return contactId, nil
+}
+
+func (r *contactRepository) DeleteContact(ctx context.Context, delete *models.DeleteContactRequest) (contactId int64, err error) {
+	query := `DELETE FROM contacts WHERE account_id = $1 AND contact_id = $2 RETURNING contact_id`
+	err = r.db.QueryRowContext(ctx, query, delete.AccountID, delete.ContactID).Scan(&contactId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return contactId, nil
+}
@@ -0,0 +1,69 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestContactCRUD_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewContactRepository(db)
+
+	contact := models.Contact{
+		AccountID:    1,
+		Email:        "jane.doe@example.com",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		Company:      "Acme",
+		Timezone:     "Europe/Zagreb",
+		CustomFields: map[string]string{"title": "CTO"},
+	}
+
+	ctx := context.Background()
+	contactId, err := repo.AddContact(ctx, &contact)
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+
+	// The same address can't be added twice to an account
+	if _, err := repo.AddContact(ctx, &contact); !errors.Is(err, persistence.ErrConflict) {
+		t.Fatalf("expected ErrConflict adding a duplicate contact, got %v", err)
+	}
+
+	update := models.UpdateContactRequest{
+		AccountID: 1,
+		ContactID: contactId,
+		Company:   &[]string{"Initech"}[0],
+	}
+	if _, err := repo.UpdateContact(ctx, &update); err != nil {
+		t.Fatalf("failed to update contact: %v", err)
+	}
+
+	gotContact, err := repo.GetContact(ctx, &models.GetContactRequest{AccountID: 1, ContactID: contactId})
+	if err != nil {
+		t.Fatalf("failed to get contact: %v", err)
+	}
+	if gotContact.Company != "Initech" || gotContact.FirstName != "Jane" || gotContact.CustomFields["title"] != "CTO" {
+		t.Fatalf("expected only the company to change, got %+v", gotContact)
+	}
+
+	contacts, next, err := repo.ListContacts(ctx, &models.ListContactsRequest{AccountID: 1, Email: "jane", Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to list contacts: %v", err)
+	}
+	if len(contacts) != 1 || next != nil {
+		t.Fatalf("expected a single page with one contact, got %+v and %+v", contacts, next)
+	}
+
+	delete := models.DeleteContactRequest{AccountID: 1, ContactID: contactId}
+	if _, err := repo.DeleteContact(ctx, &delete); err != nil {
+		t.Fatalf("failed to delete contact: %v", err)
+	}
+	if _, err := repo.GetContact(ctx, &models.GetContactRequest{AccountID: 1, ContactID: contactId}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"salesforge-api/internal/models"
+	"time"
+)
+
+type EnrollmentRepository interface {
+	AddEnrollments(ctx context.Context, enrollment *models.Enrollment, contactIds []int64) (enrollments []models.Enrollment, skippedContactIds []int64, err error)
+	UpdateEnrollment(ctx context.Context, update *models.UpdateEnrollmentRequest, resume ResumeFunc) (enrollmentId int64, err error)
+	DeleteEnrollment(ctx context.Context, delete *models.DeleteEnrollmentRequest) (enrollmentId int64, err error)
+}
+
+// ResumeFunc reschedules an enrollment that is resumed, given the steps of
+// its sequence in order.
+type ResumeFunc func(enrollment *models.Enrollment, steps []models.Step)
+
+type enrollmentRepository struct {
+	db *sql.DB
+}
+
+func NewEnrollmentRepository(db *sql.DB) EnrollmentRepository {
+	return &enrollmentRepository{
+		db: db,
+	}
+}
+
+// AddEnrollments enrolls every contact with the status and schedule of the
+// given enrollment. Contacts already in the sequence are skipped; contacts
+// that don't belong to the account fail the whole request.
+func (r *enrollmentRepository) AddEnrollments(ctx context.Context, enrollment *models.Enrollment, contactIds []int64) (enrollments []models.Enrollment, skippedContactIds []int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	createdAt := time.Now().Unix()
+	enrollments = []models.Enrollment{}
+	skippedContactIds = []int64{}
+	for _, contactId := range contactIds {
+		added := *enrollment
+		added.ContactID = contactId
+		added.CreatedAt = createdAt
+
+		enrollmentId, err := r.addEnrollment(ctx, tx, &added)
+		if err != nil {
+			return nil, nil, err
+		}
+		if enrollmentId == 0 {
+			skippedContactIds = append(skippedContactIds, contactId)
+			continue
+		}
+		added.EnrollmentID = enrollmentId
+		enrollments = append(enrollments, added)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return enrollments, skippedContactIds, nil
+}
+
+// addEnrollment returns a zero id when the contact is already enrolled.
+func (r *enrollmentRepository) addEnrollment(ctx context.Context, tx *sql.Tx, enrollment *models.Enrollment) (enrollmentId int64, err error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM contacts WHERE account_id = $1 AND contact_id = $2)`
+	err = tx.QueryRowContext(ctx, query, enrollment.AccountID, enrollment.ContactID).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("%w: contact %d does not exist", ErrValidation, enrollment.ContactID)
+	}
+
+	query = `INSERT INTO enrollments (account_id, sequence_id, contact_id, created_at, status, current_step, current_step_id, next_send_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (sequence_id, contact_id) DO NOTHING RETURNING enrollment_id`
+	err = tx.QueryRowContext(ctx, query, enrollment.AccountID, enrollment.SequenceID, enrollment.ContactID, enrollment.CreatedAt, enrollment.Status, enrollment.CurrentStep, nullIfZero(enrollment.CurrentStepID), nullIfZero(enrollment.NextSendAt)).Scan(&enrollmentId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return enrollmentId, nil
+}
+
+func nullIfZero(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
+
+// UpdateEnrollment pauses or resumes an enrollment. Resumed enrollments are
+// rescheduled by resume while the enrollment is locked.
+func (r *enrollmentRepository) UpdateEnrollment(ctx context.Context, update *models.UpdateEnrollmentRequest, resume ResumeFunc) (enrollmentId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	enrollment := models.Enrollment{AccountID: update.AccountID, EnrollmentID: update.EnrollmentID}
+	var currentStepId, nextSendAt sql.NullInt64
+	query := `SELECT sequence_id, status, current_step, current_step_id, next_send_at FROM enrollments WHERE account_id = $1 AND enrollment_id = $2 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, update.AccountID, update.EnrollmentID).Scan(&enrollment.SequenceID, &enrollment.Status, &enrollment.CurrentStep, &currentStepId, &nextSendAt)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	if required := models.EnrollmentTransitions[update.Status]; enrollment.Status != required {
+		return 0, fmt.Errorf("%w: enrollment is %s, expected %s", ErrConflict, enrollment.Status, required)
+	}
+	enrollment.Status = update.Status
+	enrollment.CurrentStepID = currentStepId.Int64
+	enrollment.NextSendAt = nextSendAt.Int64
+
+	if update.Status == models.EnrollmentStatusActive && resume != nil {
+		sequences := &sequenceRepository{db: r.db}
+		steps, err := sequences.getSteps(ctx, tx, update.AccountID, enrollment.SequenceID)
+		if err != nil {
+			return 0, err
+		}
+		resume(&enrollment, steps)
+	}
+
+	query = `UPDATE enrollments SET status = $1, current_step = $2, current_step_id = $3, next_send_at = $4, updated_at = $5 WHERE enrollment_id = $6 RETURNING enrollment_id`
+	updatedAt := time.Now().Unix()
+	err = tx.QueryRowContext(ctx, query, enrollment.Status, enrollment.CurrentStep, nullIfZero(enrollment.CurrentStepID), nullIfZero(enrollment.NextSendAt), updatedAt, update.EnrollmentID).Scan(&enrollmentId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return enrollmentId, nil
+}
+
+func (r *enrollmentRepository) DeleteEnrollment(ctx context.Context, delete *models.DeleteEnrollmentRequest) (enrollmentId int64, err error) {
+	query := `DELETE FROM enrollments WHERE account_id = $1 AND enrollment_id = $2 RETURNING enrollment_id`
+	err = r.db.QueryRowContext(ctx, query, delete.AccountID, delete.EnrollmentID).Scan(&enrollmentId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return enrollmentId, nil
+}
@@ -0,0 +1,96 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestEnrollmentLifecycle_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	repo := persistence.NewEnrollmentRepository(db)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1},
+	}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: "jane.doe@example.com", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+
+	enrollment := models.Enrollment{
+		AccountID:     1,
+		SequenceID:    sequenceId,
+		Status:        models.EnrollmentStatusActive,
+		CurrentStep:   1,
+		CurrentStepID: 1,
+		NextSendAt:    1706132001,
+	}
+	enrollments, skipped, err := repo.AddEnrollments(ctx, &enrollment, []int64{contactId})
+	if err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+	if len(enrollments) != 1 || len(skipped) != 0 {
+		t.Fatalf("expected one enrollment and no skipped contacts, got %+v and %v", enrollments, skipped)
+	}
+
+	// Enrolling the same contact again is skipped rather than failing
+	enrollments, skipped, err = repo.AddEnrollments(ctx, &enrollment, []int64{contactId})
+	if err != nil {
+		t.Fatalf("failed to re-add enrollments: %v", err)
+	}
+	if len(enrollments) != 0 || len(skipped) != 1 || skipped[0] != contactId {
+		t.Fatalf("expected contact %d to be skipped, got %+v and %v", contactId, enrollments, skipped)
+	}
+
+	// Contacts of another account can't be enrolled
+	if _, _, err := repo.AddEnrollments(ctx, &enrollment, []int64{contactId + 1}); !errors.Is(err, persistence.ErrValidation) {
+		t.Fatalf("expected ErrValidation enrolling an unknown contact, got %v", err)
+	}
+
+	pause := models.UpdateEnrollmentRequest{AccountID: 1, EnrollmentID: 1, Status: models.EnrollmentStatusPaused}
+	if _, err := repo.UpdateEnrollment(ctx, &pause, nil); err != nil {
+		t.Fatalf("failed to pause enrollment: %v", err)
+	}
+	if _, err := repo.UpdateEnrollment(ctx, &pause, nil); !errors.Is(err, persistence.ErrConflict) {
+		t.Fatalf("expected ErrConflict pausing a paused enrollment, got %v", err)
+	}
+
+	resume := models.UpdateEnrollmentRequest{AccountID: 1, EnrollmentID: 1, Status: models.EnrollmentStatusActive}
+	resumeFunc := func(enrollment *models.Enrollment, steps []models.Step) {
+		if enrollment.CurrentStepID != 1 || len(steps) != 1 {
+			t.Fatalf("unexpected enrollment to resume %+v", enrollment)
+		}
+		enrollment.NextSendAt = 1706304801
+	}
+	if _, err := repo.UpdateEnrollment(ctx, &resume, resumeFunc); err != nil {
+		t.Fatalf("failed to resume enrollment: %v", err)
+	}
+	var status string
+	var nextSendAt int64
+	query := `SELECT status, next_send_at FROM enrollments WHERE enrollment_id = $1`
+	if err := db.QueryRow(query, 1).Scan(&status, &nextSendAt); err != nil {
+		t.Fatalf("failed to get enrollment: %v", err)
+	}
+	if status != models.EnrollmentStatusActive || nextSendAt != 1706304801 {
+		t.Fatalf("expected the resumed enrollment to be rescheduled, got %s at %d", status, nextSendAt)
+	}
+
+	if _, err := repo.DeleteEnrollment(ctx, &models.DeleteEnrollmentRequest{AccountID: 1, EnrollmentID: 1}); err != nil {
+		t.Fatalf("failed to delete enrollment: %v", err)
+	}
+	if _, err := repo.DeleteEnrollment(ctx, &models.DeleteEnrollmentRequest{AccountID: 1, EnrollmentID: 1}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting a removed enrollment, got %v", err)
+	}
+}
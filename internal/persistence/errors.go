@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+// Domain errors returned by the repositories. Callers match them with
+// errors.Is; the original database error stays in the chain.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+)
+
+// translateError maps driver errors to the domain errors above and returns
+// any other error unchanged.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation, pqForeignKeyViolation:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		case pqCheckViolation:
+			return fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+	return err
+}
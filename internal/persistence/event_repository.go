@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"salesforge-api/internal/models"
+	"time"
+)
+
+type EventRepository interface {
+	AddEvent(ctx context.Context, event *models.Event) (eventId int64, err error)
+	AddReply(ctx context.Context, accountId int64, messageId string) (eventId int64, err error)
+}
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{
+		db: db,
+	}
+}
+
+func (r *eventRepository) AddEvent(ctx context.Context, event *models.Event) (eventId int64, err error) {
+	query := `INSERT INTO events (account_id, sequence_id, enrollment_id, step_id, type, created_at, user_agent, ip_address, url) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING event_id`
+	createdAt := time.Now().Unix()
+	err = r.db.QueryRowContext(ctx, query, event.AccountID, event.SequenceID, event.EnrollmentID, event.StepID, event.Type, createdAt, event.UserAgent, event.IPAddress, event.URL).Scan(&eventId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return eventId, nil
+}
+
+// AddReply records a reply event for the account's send with messageId,
+// failing with ErrNotFound when there is none. The enrollment the send
+// belongs to is marked replied, so that no more steps are sent, unless it
+// has ended already.
+func (r *eventRepository) AddReply(ctx context.Context, accountId int64, messageId string) (eventId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var enrollmentId int64
+	query := `INSERT INTO events (account_id, sequence_id, enrollment_id, step_id, type, created_at) SELECT s.account_id, e.sequence_id, s.enrollment_id, s.step_id, $1, $2 FROM sends s JOIN enrollments e ON e.enrollment_id = s.enrollment_id WHERE s.account_id = $3 AND s.message_id = $4 AND s.status = $5 LIMIT 1 RETURNING event_id, enrollment_id`
+	createdAt := time.Now().Unix()
+	err = tx.QueryRowContext(ctx, query, models.EventTypeReply, createdAt, accountId, messageId, models.SendStatusSent).Scan(&eventId, &enrollmentId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	query = `UPDATE enrollments SET status = $1, updated_at = $2 WHERE enrollment_id = $3 AND status IN ($4, $5)`
+	_, err = tx.ExecContext(ctx, query, models.EnrollmentStatusReplied, createdAt, enrollmentId, models.EnrollmentStatusActive, models.EnrollmentStatusPaused)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return eventId, nil
+}
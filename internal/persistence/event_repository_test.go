@@ -0,0 +1,140 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestAddEvent_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	repo := persistence.NewEventRepository(db)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence", SequenceOpenTrackingEnabled: true}
+	steps := []models.Step{{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1"}}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: "jane.doe@example.com", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	enrollments, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, []int64{contactId})
+	if err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	event := models.Event{
+		AccountID:    1,
+		SequenceID:   sequenceId,
+		EnrollmentID: enrollments[0].EnrollmentID,
+		StepID:       1,
+		Type:         models.EventTypeOpen,
+		UserAgent:    "Mozilla/5.0",
+		IPAddress:    "203.0.113.7",
+	}
+	eventId, err := repo.AddEvent(ctx, &event)
+	if err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	if eventId != 1 {
+		t.Fatalf("expected event id 1, got %d", eventId)
+	}
+}
+
+func TestAddReply_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	sendRepo := persistence.NewSendRepository(db, testCipher)
+	repo := persistence.NewEventRepository(db)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1"},
+		{StepEmailSubject: "Subject 2", StepEmailBody: "Body 2", WaitDays: 2},
+	}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: "jane.doe@example.com", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	if _, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, []int64{contactId}); err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	var claimed *models.DueSend
+	found, err := sendRepo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+		due.Send.Attempts = 1
+		due.Send.Status = models.SendStatusSending
+		claimed = due
+		return nil
+	})
+	if err != nil || !found {
+		t.Fatalf("expected a due send, got %v and %v", found, err)
+	}
+
+	// Nothing was sent yet to reply to
+	if _, err := repo.AddReply(ctx, 1, "abc@sales.example.com"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	claimed.Send.Status = models.SendStatusSent
+	claimed.Send.SentAt = 1706132001
+	claimed.Send.MessageID = "abc@sales.example.com"
+	claimed.Enrollment.CurrentStep = 2
+	claimed.Enrollment.CurrentStepID = 2
+	claimed.Enrollment.NextSendAt = 1706304801
+	if err := sendRepo.RecordSend(ctx, claimed); err != nil {
+		t.Fatalf("failed to record send: %v", err)
+	}
+
+	if _, err := repo.AddReply(ctx, 2, "abc@sales.example.com"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected another account's send not to be found, got %v", err)
+	}
+	eventId, err := repo.AddReply(ctx, 1, "abc@sales.example.com")
+	if err != nil {
+		t.Fatalf("failed to add reply: %v", err)
+	}
+
+	var event models.Event
+	query := `SELECT sequence_id, enrollment_id, step_id, type FROM events WHERE event_id = $1`
+	if err := db.QueryRow(query, eventId).Scan(&event.SequenceID, &event.EnrollmentID, &event.StepID, &event.Type); err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if event.SequenceID != sequenceId || event.EnrollmentID != claimed.Enrollment.EnrollmentID || event.StepID != claimed.Step.StepID || event.Type != models.EventTypeReply {
+		t.Fatalf("unexpected reply event %+v", event)
+	}
+
+	// The contact replied, so the second step isn't sent
+	var status string
+	query = `SELECT status FROM enrollments WHERE enrollment_id = $1`
+	if err := db.QueryRow(query, claimed.Enrollment.EnrollmentID).Scan(&status); err != nil {
+		t.Fatalf("failed to get enrollment: %v", err)
+	}
+	if status != models.EnrollmentStatusReplied {
+		t.Fatalf("expected the enrollment to be replied, got %s", status)
+	}
+	found, err = sendRepo.ProcessDueSend(ctx, 1706304801, func(ctx context.Context, due *models.DueSend) error {
+		t.Fatalf("unexpected due send %+v", due)
+		return nil
+	})
+	if err != nil || found {
+		t.Fatalf("expected nothing due, got %v and %v", found, err)
+	}
+}
@@ -0,0 +1,351 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/secrets"
+	"sort"
+	"time"
+)
+
+type MailboxRepository interface {
+	AddMailbox(ctx context.Context, mailbox *models.Mailbox) (mailboxId int64, err error)
+	GetMailbox(ctx context.Context, get *models.GetMailboxRequest) (mailbox *models.Mailbox, err error)
+	ListMailboxes(ctx context.Context, list *models.ListMailboxesRequest) (mailboxes []models.Mailbox, err error)
+	UpdateMailbox(ctx context.Context, update *models.UpdateMailboxRequest) (mailboxId int64, err error)
+	DeleteMailbox(ctx context.Context, delete *models.DeleteMailboxRequest) (mailboxId int64, err error)
+	GetSequenceMailboxes(ctx context.Context, get *models.GetSequenceMailboxesRequest) (mailboxIds []int64, err error)
+	SetSequenceMailboxes(ctx context.Context, set *models.SetSequenceMailboxesRequest) (sequenceId int64, err error)
+}
+
+type mailboxRepository struct {
+	db        *sql.DB
+	sequences *sequenceRepository
+	cipher    *secrets.Cipher
+}
+
+// NewMailboxRepository stores SMTP passwords encrypted with cipher.
+func NewMailboxRepository(db *sql.DB, cipher *secrets.Cipher) MailboxRepository {
+	return &mailboxRepository{
+		db:        db,
+		sequences: &sequenceRepository{db: db},
+		cipher:    cipher,
+	}
+}
+
+const mailboxColumns = `account_id, mailbox_id, created_at, updated_at, email, from_name, smtp_host, smtp_port, smtp_username, smtp_password, daily_limit, warmup_start_limit, warmup_daily_increase, consecutive_failures, last_failed_at, sent_day, sent_count`
+
+func scanMailbox(row rowScanner, now time.Time) (*models.Mailbox, error) {
+	var mailbox models.Mailbox
+	var updatedAt sql.NullInt64
+	var lastFailedAt sql.NullInt64
+	err := row.Scan(&mailbox.AccountID, &mailbox.MailboxID, &mailbox.CreatedAt, &updatedAt, &mailbox.Email, &mailbox.FromName, &mailbox.SmtpHost, &mailbox.SmtpPort, &mailbox.SmtpUsername, &mailbox.SmtpPassword, &mailbox.DailyLimit, &mailbox.WarmupStartLimit, &mailbox.WarmupDailyIncrease, &mailbox.ConsecutiveFailures, &lastFailedAt, &mailbox.SentDay, &mailbox.SentCount)
+	if err != nil {
+		return nil, err
+	}
+	mailbox.UpdatedAt = updatedAt.Int64
+	mailbox.LastFailedAt = lastFailedAt.Int64
+	mailbox.Refresh(now)
+
+	return &mailbox, nil
+}
+
+func (r *mailboxRepository) AddMailbox(ctx context.Context, mailbox *models.Mailbox) (mailboxId int64, err error) {
+	smtpPassword, err := r.cipher.Encrypt(mailbox.SmtpPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO mailboxes (account_id, created_at, email, from_name, smtp_host, smtp_port, smtp_username, smtp_password, daily_limit, warmup_start_limit, warmup_daily_increase) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING mailbox_id`
+	createdAt := time.Now().Unix()
+	err = r.db.QueryRowContext(ctx, query, mailbox.AccountID, createdAt, mailbox.Email, mailbox.FromName, mailbox.SmtpHost, mailbox.SmtpPort, mailbox.SmtpUsername, smtpPassword, mailbox.DailyLimit, mailbox.WarmupStartLimit, mailbox.WarmupDailyIncrease).Scan(&mailboxId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return mailboxId, nil
+}
+
+// GetMailbox never returns the SMTP password.
+func (r *mailboxRepository) GetMailbox(ctx context.Context, get *models.GetMailboxRequest) (mailbox *models.Mailbox, err error) {
+	query := `SELECT ` + mailboxColumns + ` FROM mailboxes WHERE account_id = $1 AND mailbox_id = $2`
+	mailbox, err = scanMailbox(r.db.QueryRowContext(ctx, query, get.AccountID, get.MailboxID), time.Now())
+	if err != nil {
+		return nil, translateError(err)
+	}
+	mailbox.SmtpPassword = ""
+
+	return mailbox, nil
+}
+
+// ListMailboxes never returns the SMTP passwords.
+func (r *mailboxRepository) ListMailboxes(ctx context.Context, list *models.ListMailboxesRequest) (mailboxes []models.Mailbox, err error) {
+	query := `SELECT ` + mailboxColumns + ` FROM mailboxes WHERE account_id = $1 ORDER BY mailbox_id`
+	rows, err := r.db.QueryContext(ctx, query, list.AccountID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	mailboxes = []models.Mailbox{}
+	for rows.Next() {
+		mailbox, err := scanMailbox(rows, now)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		mailbox.SmtpPassword = ""
+		mailboxes = append(mailboxes, *mailbox)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+
+	return mailboxes, nil
+}
+
+func (r *mailboxRepository) UpdateMailbox(ctx context.Context, update *models.UpdateMailboxRequest) (mailboxId int64, err error) {
+	u := &updateBuilder{}
+	if update.FromName != nil {
+		u.set("from_name", *update.FromName)
+	}
+	if update.SmtpHost != nil {
+		u.set("smtp_host", *update.SmtpHost)
+	}
+	if update.SmtpPort != nil {
+		u.set("smtp_port", *update.SmtpPort)
+	}
+	if update.SmtpUsername != nil {
+		u.set("smtp_username", *update.SmtpUsername)
+	}
+	if update.SmtpPassword != nil {
+		smtpPassword, err := r.cipher.Encrypt(*update.SmtpPassword)
+		if err != nil {
+			return 0, err
+		}
+		u.set("smtp_password", smtpPassword)
+	}
+	if update.SmtpChanged() {
+		u.set("consecutive_failures", 0)
+	}
+	if update.DailyLimit != nil {
+		u.set("daily_limit", *update.DailyLimit)
+	}
+	if update.WarmupStartLimit != nil {
+		u.set("warmup_start_limit", *update.WarmupStartLimit)
+	}
+	if update.WarmupDailyIncrease != nil {
+		u.set("warmup_daily_increase", *update.WarmupDailyIncrease)
+	}
+	u.set("updated_at", time.Now().Unix())
+
+	query := `UPDATE mailboxes SET ` + u.clause() + ` WHERE account_id = ` + u.arg(update.AccountID) + ` AND mailbox_id = ` + u.arg(update.MailboxID) + ` RETURNING mailbox_id`
+	err = r.db.QueryRowContext(ctx, query, u.args...).Scan(&mailboxId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return mailboxId, nil
+}
+
+func (r *mailboxRepository) DeleteMailbox(ctx context.Context, delete *models.DeleteMailboxRequest) (mailboxId int64, err error) {
+	query := `DELETE FROM mailboxes WHERE account_id = $1 AND mailbox_id = $2 RETURNING mailbox_id`
+	err = r.db.QueryRowContext(ctx, query, delete.AccountID, delete.MailboxID).Scan(&mailboxId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return mailboxId, nil
+}
+
+func (r *mailboxRepository) GetSequenceMailboxes(ctx context.Context, get *models.GetSequenceMailboxesRequest) (mailboxIds []int64, err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = r.sequences.getSequence(ctx, tx, get.AccountID, get.SequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	mailboxIds, err = getSequenceMailboxIds(ctx, tx, get.SequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return mailboxIds, nil
+}
+
+func getSequenceMailboxIds(ctx context.Context, tx *sql.Tx, sequenceId int64) ([]int64, error) {
+	query := `SELECT mailbox_id FROM sequence_mailboxes WHERE sequence_id = $1 ORDER BY mailbox_id`
+	rows, err := tx.QueryContext(ctx, query, sequenceId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mailboxIds := []int64{}
+	for rows.Next() {
+		var mailboxId int64
+		if err := rows.Scan(&mailboxId); err != nil {
+			return nil, err
+		}
+		mailboxIds = append(mailboxIds, mailboxId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mailboxIds, nil
+}
+
+// SetSequenceMailboxes replaces the mailboxes the sequence is pinned to.
+// Every mailbox must belong to the sequence's account.
+func (r *mailboxRepository) SetSequenceMailboxes(ctx context.Context, set *models.SetSequenceMailboxesRequest) (sequenceId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = r.sequences.lockSequence(ctx, tx, set.AccountID, set.SequenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	mailboxIds := uniqueIds(set.MailboxIDs)
+	var found int
+	query := `SELECT COUNT(*) FROM mailboxes WHERE account_id = $1 AND mailbox_id = ANY($2)`
+	err = tx.QueryRowContext(ctx, query, set.AccountID, pq.Array(mailboxIds)).Scan(&found)
+	if err != nil {
+		return 0, err
+	}
+	if found != len(mailboxIds) {
+		return 0, fmt.Errorf("%w: not every mailbox exists", ErrValidation)
+	}
+
+	query = `DELETE FROM sequence_mailboxes WHERE sequence_id = $1`
+	_, err = tx.ExecContext(ctx, query, set.SequenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	query = `INSERT INTO sequence_mailboxes (sequence_id, mailbox_id) SELECT $1, unnest($2::BIGINT[])`
+	_, err = tx.ExecContext(ctx, query, set.SequenceID, pq.Array(mailboxIds))
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return set.SequenceID, nil
+}
+
+func uniqueIds(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := []int64{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// lockMailbox picks the least used mailbox the enrollment may send from that
+// is healthy and under today's limit, and locks it. Sequences pinned to
+// mailboxes only use those. available is false when the account has
+// mailboxes but none can send right now. When it has none at all mailbox is
+// nil and available true. The SMTP password of mailbox is decrypted.
+func lockMailbox(ctx context.Context, tx *sql.Tx, cipher *secrets.Cipher, accountId int64, sequenceId int64, now time.Time) (mailbox *models.Mailbox, available bool, err error) {
+	query := `SELECT ` + mailboxColumns + ` FROM mailboxes WHERE account_id = $1 AND (mailbox_id IN (SELECT mailbox_id FROM sequence_mailboxes WHERE sequence_id = $2) OR NOT EXISTS (SELECT 1 FROM sequence_mailboxes WHERE sequence_id = $2))`
+	rows, err := tx.QueryContext(ctx, query, accountId, sequenceId)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	candidates := []models.Mailbox{}
+	for rows.Next() {
+		candidate, err := scanMailbox(rows, now)
+		if err != nil {
+			return nil, false, err
+		}
+		candidates = append(candidates, *candidate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(candidates) == 0 {
+		return nil, true, nil
+	}
+
+	// Rotate by sending from the mailbox that sent the least today.
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].SentToday != candidates[j].SentToday {
+			return candidates[i].SentToday < candidates[j].SentToday
+		}
+		return candidates[i].MailboxID < candidates[j].MailboxID
+	})
+
+	// Mailboxes locked by another replica are skipped, and the rest are
+	// checked again once locked in case they sent in the meantime.
+	query = `SELECT ` + mailboxColumns + ` FROM mailboxes WHERE mailbox_id = $1 FOR UPDATE SKIP LOCKED`
+	for _, candidate := range candidates {
+		if !candidate.Available() {
+			continue
+		}
+		mailbox, err := scanMailbox(tx.QueryRowContext(ctx, query, candidate.MailboxID), now)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if mailbox.Available() {
+			mailbox.SmtpPassword, err = cipher.Decrypt(mailbox.SmtpPassword)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to decrypt password of mailbox %d: %w", mailbox.MailboxID, err)
+			}
+			return mailbox, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// reserveMailboxSend counts a send about to be delivered against the
+// mailbox's daily limit, so that the mailbox needn't stay locked while it is.
+func reserveMailboxSend(ctx context.Context, tx *sql.Tx, mailboxId int64, now time.Time) error {
+	query := `UPDATE mailboxes SET sent_count = CASE WHEN sent_day = $1 THEN sent_count + 1 ELSE 1 END, sent_day = $1 WHERE mailbox_id = $2`
+	_, err := tx.ExecContext(ctx, query, models.MailboxDay(now), mailboxId)
+	return err
+}
+
+// recordMailboxSend records the outcome of a reserved send. A failed send
+// counts against the mailbox's health instead of its daily limit.
+func recordMailboxSend(ctx context.Context, tx *sql.Tx, mailboxId int64, sent bool, now time.Time) error {
+	if sent {
+		query := `UPDATE mailboxes SET consecutive_failures = 0 WHERE mailbox_id = $1`
+		_, err := tx.ExecContext(ctx, query, mailboxId)
+		return err
+	}
+	query := `UPDATE mailboxes SET sent_count = CASE WHEN sent_day = $1 AND sent_count > 0 THEN sent_count - 1 ELSE sent_count END, consecutive_failures = consecutive_failures + 1, last_failed_at = $2 WHERE mailbox_id = $3`
+	_, err := tx.ExecContext(ctx, query, models.MailboxDay(now), now.Unix(), mailboxId)
+	return err
+}
@@ -0,0 +1,129 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestMailboxRotation_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	repo := persistence.NewMailboxRepository(db, testCipher)
+	sendRepo := persistence.NewSendRepository(db, testCipher)
+
+	ctx := context.Background()
+	var mailboxIds []int64
+	for _, email := range []string{"jon@example.com", "ana@example.com", "ivo@example.com"} {
+		mailboxId, err := repo.AddMailbox(ctx, &models.Mailbox{AccountID: 1, Email: email, SmtpHost: "smtp.example.com", SmtpPort: 587, SmtpPassword: "secret", DailyLimit: 1})
+		if err != nil {
+			t.Fatalf("failed to add mailbox: %v", err)
+		}
+		mailboxIds = append(mailboxIds, mailboxId)
+	}
+
+	mailboxes, err := repo.ListMailboxes(ctx, &models.ListMailboxesRequest{AccountID: 1})
+	if err != nil {
+		t.Fatalf("failed to list mailboxes: %v", err)
+	}
+	if len(mailboxes) != 3 || mailboxes[0].SmtpPassword != "" || !mailboxes[0].Healthy || mailboxes[0].LimitToday != 1 {
+		t.Fatalf("unexpected mailboxes %+v", mailboxes)
+	}
+
+	// Passwords are stored encrypted
+	var stored string
+	if err := db.QueryRow(`SELECT smtp_password FROM mailboxes WHERE mailbox_id = $1`, mailboxIds[0]).Scan(&stored); err != nil {
+		t.Fatalf("failed to read password: %v", err)
+	}
+	if plaintext, err := testCipher.Decrypt(stored); stored == "secret" || err != nil || plaintext != "secret" {
+		t.Fatalf("expected an encrypted password, got %q", stored)
+	}
+
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1"}}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	// Mailboxes of another account can't be pinned
+	set := models.SetSequenceMailboxesRequest{AccountID: 1, SequenceID: sequenceId, MailboxIDs: []int64{mailboxIds[0], 99}}
+	if _, err := repo.SetSequenceMailboxes(ctx, &set); !errors.Is(err, persistence.ErrValidation) {
+		t.Fatalf("expected ErrValidation pinning an unknown mailbox, got %v", err)
+	}
+	set.MailboxIDs = []int64{mailboxIds[0], mailboxIds[1]}
+	if _, err := repo.SetSequenceMailboxes(ctx, &set); err != nil {
+		t.Fatalf("failed to pin mailboxes: %v", err)
+	}
+	pinned, err := repo.GetSequenceMailboxes(ctx, &models.GetSequenceMailboxesRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil || len(pinned) != 2 {
+		t.Fatalf("expected two pinned mailboxes, got %v and %v", pinned, err)
+	}
+
+	var contactIds []int64
+	for _, email := range []string{"jane@example.com", "john@example.com", "mia@example.com"} {
+		contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: email, Timezone: "UTC"})
+		if err != nil {
+			t.Fatalf("failed to add contact: %v", err)
+		}
+		contactIds = append(contactIds, contactId)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	if _, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, contactIds); err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	// Each pinned mailbox sends once, then both are at their limit
+	now := time.Now().Unix()
+	used := map[int64]bool{}
+	for i := 0; i < 3; i++ {
+		var claimed *models.DueSend
+		_, err := sendRepo.ProcessDueSend(ctx, now, func(ctx context.Context, due *models.DueSend) error {
+			if i == 2 {
+				if due.Mailbox != nil || !due.MailboxUnavailable {
+					t.Fatalf("expected no mailbox to be available, got %+v", due.Mailbox)
+				}
+				due.Enrollment.NextSendAt = now + 600
+				return nil
+			}
+			if due.Mailbox == nil || due.Mailbox.MailboxID == mailboxIds[2] || used[due.Mailbox.MailboxID] || due.Mailbox.SmtpPassword != "secret" {
+				t.Fatalf("expected an unused pinned mailbox, got %+v", due.Mailbox)
+			}
+			used[due.Mailbox.MailboxID] = true
+			due.Send.Attempts = 1
+			due.Send.Status = models.SendStatusSending
+			claimed = due
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to process due send: %v", err)
+		}
+		if claimed == nil {
+			continue
+		}
+
+		claimed.Send.Status = models.SendStatusSent
+		claimed.Send.SentAt = now
+		claimed.Enrollment.Status = models.EnrollmentStatusFinished
+		claimed.Enrollment.CurrentStep = 0
+		claimed.Enrollment.CurrentStepID = 0
+		claimed.Enrollment.NextSendAt = 0
+		if err := sendRepo.RecordSend(ctx, claimed); err != nil {
+			t.Fatalf("failed to record send: %v", err)
+		}
+	}
+
+	mailbox, err := repo.GetMailbox(ctx, &models.GetMailboxRequest{AccountID: 1, MailboxID: mailboxIds[0]})
+	if err != nil {
+		t.Fatalf("failed to get mailbox: %v", err)
+	}
+	if mailbox.SentToday != 1 || mailbox.Available() {
+		t.Fatalf("expected mailbox to be at its limit, got %+v", mailbox)
+	}
+}
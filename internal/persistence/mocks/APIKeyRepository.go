@@ -0,0 +1,165 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// APIKeyRepository is an autogenerated mock type for the APIKeyRepository type
+type APIKeyRepository struct {
+	mock.Mock
+}
+
+// AddAPIKey provides a mock function with given fields: ctx, apiKey
+func (_m *APIKeyRepository) AddAPIKey(ctx context.Context, apiKey *models.APIKey) (int64, error) {
+	ret := _m.Called(ctx, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAPIKey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.APIKey) (int64, error)); ok {
+		return rf(ctx, apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.APIKey) int64); ok {
+		r0 = rf(ctx, apiKey)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.APIKey) error); ok {
+		r1 = rf(ctx, apiKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeyByPrefix provides a mock function with given fields: ctx, prefix
+func (_m *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	ret := _m.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByPrefix")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.APIKey, error)); ok {
+		return rf(ctx, prefix)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.APIKey); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx, list
+func (_m *APIKeyRepository) ListAPIKeys(ctx context.Context, list *models.ListAPIKeysRequest) ([]models.APIKey, error) {
+	ret := _m.Called(ctx, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListAPIKeysRequest) ([]models.APIKey, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListAPIKeysRequest) []models.APIKey); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ListAPIKeysRequest) error); ok {
+		r1 = rf(ctx, list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, revoke
+func (_m *APIKeyRepository) RevokeAPIKey(ctx context.Context, revoke *models.RevokeAPIKeyRequest) (int64, error) {
+	ret := _m.Called(ctx, revoke)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RevokeAPIKeyRequest) (int64, error)); ok {
+		return rf(ctx, revoke)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RevokeAPIKeyRequest) int64); ok {
+		r0 = rf(ctx, revoke)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.RevokeAPIKeyRequest) error); ok {
+		r1 = rf(ctx, revoke)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchAPIKey provides a mock function with given fields: ctx, apiKeyId, usedAt
+func (_m *APIKeyRepository) TouchAPIKey(ctx context.Context, apiKeyId int64, usedAt time.Time) error {
+	ret := _m.Called(ctx, apiKeyId, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = rf(ctx, apiKeyId, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyRepository {
+	mock := &APIKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
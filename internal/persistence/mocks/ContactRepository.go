@@ -0,0 +1,182 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ContactRepository is an autogenerated mock type for the ContactRepository type
+type ContactRepository struct {
+	mock.Mock
+}
+
+// AddContact provides a mock function with given fields: ctx, contact
+func (_m *ContactRepository) AddContact(ctx context.Context, contact *models.Contact) (int64, error) {
+	ret := _m.Called(ctx, contact)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddContact")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Contact) (int64, error)); ok {
+		return rf(ctx, contact)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Contact) int64); ok {
+		r0 = rf(ctx, contact)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Contact) error); ok {
+		r1 = rf(ctx, contact)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteContact provides a mock function with given fields: ctx, delete
+func (_m *ContactRepository) DeleteContact(ctx context.Context, delete *models.DeleteContactRequest) (int64, error) {
+	ret := _m.Called(ctx, delete)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteContact")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteContactRequest) (int64, error)); ok {
+		return rf(ctx, delete)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteContactRequest) int64); ok {
+		r0 = rf(ctx, delete)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.DeleteContactRequest) error); ok {
+		r1 = rf(ctx, delete)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContact provides a mock function with given fields: ctx, get
+func (_m *ContactRepository) GetContact(ctx context.Context, get *models.GetContactRequest) (*models.Contact, error) {
+	ret := _m.Called(ctx, get)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetContact")
+	}
+
+	var r0 *models.Contact
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetContactRequest) (*models.Contact, error)); ok {
+		return rf(ctx, get)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetContactRequest) *models.Contact); ok {
+		r0 = rf(ctx, get)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Contact)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GetContactRequest) error); ok {
+		r1 = rf(ctx, get)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListContacts provides a mock function with given fields: ctx, list
+func (_m *ContactRepository) ListContacts(ctx context.Context, list *models.ListContactsRequest) ([]models.Contact, *models.ContactCursor, error) {
+	ret := _m.Called(ctx, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListContacts")
+	}
+
+	var r0 []models.Contact
+	var r1 *models.ContactCursor
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListContactsRequest) ([]models.Contact, *models.ContactCursor, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListContactsRequest) []models.Contact); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Contact)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ListContactsRequest) *models.ContactCursor); ok {
+		r1 = rf(ctx, list)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.ContactCursor)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.ListContactsRequest) error); ok {
+		r2 = rf(ctx, list)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateContact provides a mock function with given fields: ctx, update
+func (_m *ContactRepository) UpdateContact(ctx context.Context, update *models.UpdateContactRequest) (int64, error) {
+	ret := _m.Called(ctx, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateContact")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateContactRequest) (int64, error)); ok {
+		return rf(ctx, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateContactRequest) int64); ok {
+		r0 = rf(ctx, update)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UpdateContactRequest) error); ok {
+		r1 = rf(ctx, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewContactRepository creates a new instance of ContactRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewContactRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ContactRepository {
+	mock := &ContactRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
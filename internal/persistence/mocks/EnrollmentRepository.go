@@ -0,0 +1,126 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	persistence "salesforge-api/internal/persistence"
+)
+
+// EnrollmentRepository is an autogenerated mock type for the EnrollmentRepository type
+type EnrollmentRepository struct {
+	mock.Mock
+}
+
+// AddEnrollments provides a mock function with given fields: ctx, enrollment, contactIds
+func (_m *EnrollmentRepository) AddEnrollments(ctx context.Context, enrollment *models.Enrollment, contactIds []int64) ([]models.Enrollment, []int64, error) {
+	ret := _m.Called(ctx, enrollment, contactIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddEnrollments")
+	}
+
+	var r0 []models.Enrollment
+	var r1 []int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Enrollment, []int64) ([]models.Enrollment, []int64, error)); ok {
+		return rf(ctx, enrollment, contactIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Enrollment, []int64) []models.Enrollment); ok {
+		r0 = rf(ctx, enrollment, contactIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Enrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Enrollment, []int64) []int64); ok {
+		r1 = rf(ctx, enrollment, contactIds)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.Enrollment, []int64) error); ok {
+		r2 = rf(ctx, enrollment, contactIds)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteEnrollment provides a mock function with given fields: ctx, delete
+func (_m *EnrollmentRepository) DeleteEnrollment(ctx context.Context, delete *models.DeleteEnrollmentRequest) (int64, error) {
+	ret := _m.Called(ctx, delete)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEnrollment")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteEnrollmentRequest) (int64, error)); ok {
+		return rf(ctx, delete)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteEnrollmentRequest) int64); ok {
+		r0 = rf(ctx, delete)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.DeleteEnrollmentRequest) error); ok {
+		r1 = rf(ctx, delete)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateEnrollment provides a mock function with given fields: ctx, update, resume
+func (_m *EnrollmentRepository) UpdateEnrollment(ctx context.Context, update *models.UpdateEnrollmentRequest, resume persistence.ResumeFunc) (int64, error) {
+	ret := _m.Called(ctx, update, resume)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEnrollment")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateEnrollmentRequest, persistence.ResumeFunc) (int64, error)); ok {
+		return rf(ctx, update, resume)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateEnrollmentRequest, persistence.ResumeFunc) int64); ok {
+		r0 = rf(ctx, update, resume)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UpdateEnrollmentRequest, persistence.ResumeFunc) error); ok {
+		r1 = rf(ctx, update, resume)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEnrollmentRepository creates a new instance of EnrollmentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEnrollmentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EnrollmentRepository {
+	mock := &EnrollmentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
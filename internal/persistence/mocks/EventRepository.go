@@ -0,0 +1,85 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventRepository is an autogenerated mock type for the EventRepository type
+type EventRepository struct {
+	mock.Mock
+}
+
+// AddEvent provides a mock function with given fields: ctx, event
+func (_m *EventRepository) AddEvent(ctx context.Context, event *models.Event) (int64, error) {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddEvent")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Event) (int64, error)); ok {
+		return rf(ctx, event)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Event) int64); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Event) error); ok {
+		r1 = rf(ctx, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddReply provides a mock function with given fields: ctx, accountId, messageId
+func (_m *EventRepository) AddReply(ctx context.Context, accountId int64, messageId string) (int64, error) {
+	ret := _m.Called(ctx, accountId, messageId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddReply")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (int64, error)); ok {
+		return rf(ctx, accountId, messageId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) int64); ok {
+		r0 = rf(ctx, accountId, messageId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, accountId, messageId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEventRepository creates a new instance of EventRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventRepository {
+	mock := &EventRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,231 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MailboxRepository is an autogenerated mock type for the MailboxRepository type
+type MailboxRepository struct {
+	mock.Mock
+}
+
+// AddMailbox provides a mock function with given fields: ctx, mailbox
+func (_m *MailboxRepository) AddMailbox(ctx context.Context, mailbox *models.Mailbox) (int64, error) {
+	ret := _m.Called(ctx, mailbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMailbox")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Mailbox) (int64, error)); ok {
+		return rf(ctx, mailbox)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Mailbox) int64); ok {
+		r0 = rf(ctx, mailbox)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Mailbox) error); ok {
+		r1 = rf(ctx, mailbox)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteMailbox provides a mock function with given fields: ctx, delete
+func (_m *MailboxRepository) DeleteMailbox(ctx context.Context, delete *models.DeleteMailboxRequest) (int64, error) {
+	ret := _m.Called(ctx, delete)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMailbox")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteMailboxRequest) (int64, error)); ok {
+		return rf(ctx, delete)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteMailboxRequest) int64); ok {
+		r0 = rf(ctx, delete)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.DeleteMailboxRequest) error); ok {
+		r1 = rf(ctx, delete)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMailbox provides a mock function with given fields: ctx, get
+func (_m *MailboxRepository) GetMailbox(ctx context.Context, get *models.GetMailboxRequest) (*models.Mailbox, error) {
+	ret := _m.Called(ctx, get)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMailbox")
+	}
+
+	var r0 *models.Mailbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetMailboxRequest) (*models.Mailbox, error)); ok {
+		return rf(ctx, get)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetMailboxRequest) *models.Mailbox); ok {
+		r0 = rf(ctx, get)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Mailbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GetMailboxRequest) error); ok {
+		r1 = rf(ctx, get)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSequenceMailboxes provides a mock function with given fields: ctx, get
+func (_m *MailboxRepository) GetSequenceMailboxes(ctx context.Context, get *models.GetSequenceMailboxesRequest) ([]int64, error) {
+	ret := _m.Called(ctx, get)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSequenceMailboxes")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetSequenceMailboxesRequest) ([]int64, error)); ok {
+		return rf(ctx, get)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetSequenceMailboxesRequest) []int64); ok {
+		r0 = rf(ctx, get)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GetSequenceMailboxesRequest) error); ok {
+		r1 = rf(ctx, get)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMailboxes provides a mock function with given fields: ctx, list
+func (_m *MailboxRepository) ListMailboxes(ctx context.Context, list *models.ListMailboxesRequest) ([]models.Mailbox, error) {
+	ret := _m.Called(ctx, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMailboxes")
+	}
+
+	var r0 []models.Mailbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListMailboxesRequest) ([]models.Mailbox, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListMailboxesRequest) []models.Mailbox); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Mailbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ListMailboxesRequest) error); ok {
+		r1 = rf(ctx, list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSequenceMailboxes provides a mock function with given fields: ctx, set
+func (_m *MailboxRepository) SetSequenceMailboxes(ctx context.Context, set *models.SetSequenceMailboxesRequest) (int64, error) {
+	ret := _m.Called(ctx, set)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSequenceMailboxes")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SetSequenceMailboxesRequest) (int64, error)); ok {
+		return rf(ctx, set)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SetSequenceMailboxesRequest) int64); ok {
+		r0 = rf(ctx, set)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SetSequenceMailboxesRequest) error); ok {
+		r1 = rf(ctx, set)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateMailbox provides a mock function with given fields: ctx, update
+func (_m *MailboxRepository) UpdateMailbox(ctx context.Context, update *models.UpdateMailboxRequest) (int64, error) {
+	ret := _m.Called(ctx, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMailbox")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateMailboxRequest) (int64, error)); ok {
+		return rf(ctx, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UpdateMailboxRequest) int64); ok {
+		r0 = rf(ctx, update)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UpdateMailboxRequest) error); ok {
+		r1 = rf(ctx, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMailboxRepository creates a new instance of MailboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMailboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MailboxRepository {
+	mock := &MailboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
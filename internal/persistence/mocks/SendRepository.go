@@ -0,0 +1,95 @@
+// Code generated by mockery v2.51.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "salesforge-api/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	persistence "salesforge-api/internal/persistence"
+)
+
+// SendRepository is an autogenerated mock type for the SendRepository type
+type SendRepository struct {
+	mock.Mock
+}
+
+// PostponeEnrollment provides a mock function with given fields: ctx, enrollmentId, nextSendAt
+func (_m *SendRepository) PostponeEnrollment(ctx context.Context, enrollmentId int64, nextSendAt int64) error {
+	ret := _m.Called(ctx, enrollmentId, nextSendAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostponeEnrollment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, enrollmentId, nextSendAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProcessDueSend provides a mock function with given fields: ctx, now, process
+func (_m *SendRepository) ProcessDueSend(ctx context.Context, now int64, process persistence.ProcessFunc) (bool, error) {
+	ret := _m.Called(ctx, now, process)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProcessDueSend")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, persistence.ProcessFunc) (bool, error)); ok {
+		return rf(ctx, now, process)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, persistence.ProcessFunc) bool); ok {
+		r0 = rf(ctx, now, process)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, persistence.ProcessFunc) error); ok {
+		r1 = rf(ctx, now, process)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordSend provides a mock function with given fields: ctx, due
+func (_m *SendRepository) RecordSend(ctx context.Context, due *models.DueSend) error {
+	ret := _m.Called(ctx, due)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordSend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DueSend) error); ok {
+		r0 = rf(ctx, due)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSendRepository creates a new instance of SendRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSendRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SendRepository {
+	mock := &SendRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -42,6 +42,111 @@ func (_m *SequenceRepository) AddSequence(ctx context.Context, sequence *models.
 	return r0, r1
 }
 
+// AddStep provides a mock function with given fields: ctx, add
+func (_m *SequenceRepository) AddStep(ctx context.Context, add *models.AddStepRequest) (int64, int64, int, error) {
+	ret := _m.Called(ctx, add)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddStep")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 int
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AddStepRequest) (int64, int64, int, error)); ok {
+		return rf(ctx, add)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AddStepRequest) int64); ok {
+		r0 = rf(ctx, add)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AddStepRequest) int64); ok {
+		r1 = rf(ctx, add)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.AddStepRequest) int); ok {
+		r2 = rf(ctx, add)
+	} else {
+		r2 = ret.Get(2).(int)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, *models.AddStepRequest) error); ok {
+		r3 = rf(ctx, add)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// CloneSequence provides a mock function with given fields: ctx, clone
+func (_m *SequenceRepository) CloneSequence(ctx context.Context, clone *models.CloneSequenceRequest) (int64, int64, error) {
+	ret := _m.Called(ctx, clone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloneSequence")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.CloneSequenceRequest) (int64, int64, error)); ok {
+		return rf(ctx, clone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.CloneSequenceRequest) int64); ok {
+		r0 = rf(ctx, clone)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.CloneSequenceRequest) int64); ok {
+		r1 = rf(ctx, clone)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.CloneSequenceRequest) error); ok {
+		r2 = rf(ctx, clone)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteSequence provides a mock function with given fields: ctx, delete
+func (_m *SequenceRepository) DeleteSequence(ctx context.Context, delete *models.DeleteSequenceRequest) (int64, error) {
+	ret := _m.Called(ctx, delete)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSequence")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteSequenceRequest) (int64, error)); ok {
+		return rf(ctx, delete)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteSequenceRequest) int64); ok {
+		r0 = rf(ctx, delete)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.DeleteSequenceRequest) error); ok {
+		r1 = rf(ctx, delete)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteStep provides a mock function with given fields: ctx, delete
 func (_m *SequenceRepository) DeleteStep(ctx context.Context, delete *models.DeleteStepRequest) (int64, int64, error) {
 	ret := _m.Called(ctx, delete)
@@ -77,6 +182,181 @@ func (_m *SequenceRepository) DeleteStep(ctx context.Context, delete *models.Del
 	return r0, r1, r2
 }
 
+// GetSequence provides a mock function with given fields: ctx, get
+func (_m *SequenceRepository) GetSequence(ctx context.Context, get *models.GetSequenceRequest) (*models.Sequence, []models.Step, error) {
+	ret := _m.Called(ctx, get)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSequence")
+	}
+
+	var r0 *models.Sequence
+	var r1 []models.Step
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetSequenceRequest) (*models.Sequence, []models.Step, error)); ok {
+		return rf(ctx, get)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetSequenceRequest) *models.Sequence); ok {
+		r0 = rf(ctx, get)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Sequence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GetSequenceRequest) []models.Step); ok {
+		r1 = rf(ctx, get)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]models.Step)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.GetSequenceRequest) error); ok {
+		r2 = rf(ctx, get)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetStep provides a mock function with given fields: ctx, accountId, stepId
+func (_m *SequenceRepository) GetStep(ctx context.Context, accountId int64, stepId int64) (*models.Step, error) {
+	ret := _m.Called(ctx, accountId, stepId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStep")
+	}
+
+	var r0 *models.Step
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*models.Step, error)); ok {
+		return rf(ctx, accountId, stepId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *models.Step); ok {
+		r0 = rf(ctx, accountId, stepId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Step)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, accountId, stepId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVariantStats provides a mock function with given fields: ctx, get
+func (_m *SequenceRepository) GetVariantStats(ctx context.Context, get *models.GetVariantStatsRequest) (*models.Step, []models.VariantStats, error) {
+	ret := _m.Called(ctx, get)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVariantStats")
+	}
+
+	var r0 *models.Step
+	var r1 []models.VariantStats
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetVariantStatsRequest) (*models.Step, []models.VariantStats, error)); ok {
+		return rf(ctx, get)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetVariantStatsRequest) *models.Step); ok {
+		r0 = rf(ctx, get)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Step)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GetVariantStatsRequest) []models.VariantStats); ok {
+		r1 = rf(ctx, get)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]models.VariantStats)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.GetVariantStatsRequest) error); ok {
+		r2 = rf(ctx, get)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListSequences provides a mock function with given fields: ctx, list
+func (_m *SequenceRepository) ListSequences(ctx context.Context, list *models.ListSequencesRequest) ([]models.Sequence, *models.SequenceCursor, error) {
+	ret := _m.Called(ctx, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSequences")
+	}
+
+	var r0 []models.Sequence
+	var r1 *models.SequenceCursor
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListSequencesRequest) ([]models.Sequence, *models.SequenceCursor, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ListSequencesRequest) []models.Sequence); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Sequence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ListSequencesRequest) *models.SequenceCursor); ok {
+		r1 = rf(ctx, list)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.SequenceCursor)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.ListSequencesRequest) error); ok {
+		r2 = rf(ctx, list)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReorderSteps provides a mock function with given fields: ctx, reorder
+func (_m *SequenceRepository) ReorderSteps(ctx context.Context, reorder *models.ReorderStepsRequest) (int64, error) {
+	ret := _m.Called(ctx, reorder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReorderSteps")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ReorderStepsRequest) (int64, error)); ok {
+		return rf(ctx, reorder)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ReorderStepsRequest) int64); ok {
+		r0 = rf(ctx, reorder)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ReorderStepsRequest) error); ok {
+		r1 = rf(ctx, reorder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateSequence provides a mock function with given fields: ctx, update
 func (_m *SequenceRepository) UpdateSequence(ctx context.Context, update *models.UpdateSequenceRequest) (int64, error) {
 	ret := _m.Called(ctx, update)
@@ -1,8 +1,10 @@
 package persistence_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"os"
 	"salesforge-api/internal/config"
@@ -12,10 +14,13 @@ import (
 	_ "github.com/lib/pq"
 	"salesforge-api/internal/models"
 	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/secrets"
 )
 
 var db *sql.DB
 
+var testCipher, _ = secrets.NewCipherFromKeys(bytes.Repeat([]byte{1}, secrets.KeySize))
+
 func TestMain(m *testing.M) {
 	cfg, err := config.LoadConfig(os.DirFS("../.."))
 	if err != nil {
@@ -41,7 +46,7 @@ func TestMain(m *testing.M) {
 
 func setupTestDB() {
 	// Clean up the database before and after each test
-	_, err := db.Exec("TRUNCATE TABLE sequences, steps RESTART IDENTITY CASCADE")
+	_, err := db.Exec("TRUNCATE TABLE sequences, steps, step_variants, contacts, enrollments, sends, mailboxes, sequence_mailboxes, events, api_keys RESTART IDENTITY CASCADE")
 	if err != nil {
 		log.Fatalf("failed to clean test database: %v", err)
 	}
@@ -78,6 +83,119 @@ func TestAddSequence_Integration(t *testing.T) {
 	}
 }
 
+func TestGetSequence_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	// First, add a sequence with steps to read back
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: false,
+	}
+	steps := []models.Step{
+		{
+			StepEmailSubject:  "Subject 1",
+			StepEmailBody:     "Body 1",
+			WaitDays:          1,
+			EligibleStartTime: 1706132001,
+			EligibleEndTime:   1706304801,
+		},
+		{
+			StepEmailSubject:  "Subject 2",
+			StepEmailBody:     "Body 2",
+			WaitDays:          2,
+			EligibleStartTime: 1706132001,
+			EligibleEndTime:   1706304801,
+		},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	get := models.GetSequenceRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+	}
+
+	gotSequence, gotSteps, err := repo.GetSequence(ctx, &get)
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	if gotSequence.SequenceID != sequenceId || gotSequence.SequenceName != "Test Sequence" {
+		t.Fatalf("expected sequence %d named %q, got %d named %q", sequenceId, "Test Sequence", gotSequence.SequenceID, gotSequence.SequenceName)
+	}
+
+	if len(gotSteps) != 2 || gotSteps[0].StepEmailSubject != "Subject 1" || gotSteps[1].StepEmailSubject != "Subject 2" {
+		t.Fatalf("expected 2 ordered steps, got %+v", gotSteps)
+	}
+
+	// A different account must not see the sequence
+	get.AccountID = 2
+	if _, _, err := repo.GetSequence(ctx, &get); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound reading another account's sequence, got %v", err)
+	}
+
+	gotStep, err := repo.GetStep(ctx, 1, gotSteps[1].StepID)
+	if err != nil {
+		t.Fatalf("failed to get step: %v", err)
+	}
+	if gotStep.StepEmailSubject != "Subject 2" || gotStep.WaitDays != 2 {
+		t.Fatalf("expected the second step, got %+v", gotStep)
+	}
+	if _, err := repo.GetStep(ctx, 2, gotSteps[1].StepID); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound reading another account's step, got %v", err)
+	}
+}
+
+func TestListSequences_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	ctx := context.Background()
+	for _, name := range []string{"Alpha Outreach", "Beta Outreach", "Gamma Follow-up"} {
+		sequence := models.Sequence{
+			AccountID:                    1,
+			SequenceName:                 name,
+			SequenceOpenTrackingEnabled:  true,
+			SequenceClickTrackingEnabled: false,
+		}
+		if _, err := repo.AddSequence(ctx, &sequence, &[]models.Step{}); err != nil {
+			t.Fatalf("failed to add sequence: %v", err)
+		}
+	}
+
+	list := models.ListSequencesRequest{
+		AccountID: 1,
+		Name:      "outreach",
+		SortBy:    models.SequenceSortBySequenceID,
+		SortOrder: models.SortOrderAsc,
+		Limit:     1,
+	}
+
+	first, next, err := repo.ListSequences(ctx, &list)
+	if err != nil {
+		t.Fatalf("failed to list sequences: %v", err)
+	}
+	if len(first) != 1 || first[0].SequenceName != "Alpha Outreach" || next == nil {
+		t.Fatalf("expected first page with Alpha Outreach and a cursor, got %+v and %+v", first, next)
+	}
+
+	list.Cursor = next
+	second, next, err := repo.ListSequences(ctx, &list)
+	if err != nil {
+		t.Fatalf("failed to list sequences: %v", err)
+	}
+	if len(second) != 1 || second[0].SequenceName != "Beta Outreach" || next != nil {
+		t.Fatalf("expected last page with Beta Outreach and no cursor, got %+v and %+v", second, next)
+	}
+}
+
 func TestUpdateSequence_Integration(t *testing.T) {
 	setupTestDB()
 	repo := persistence.NewSequenceRepository(db)
@@ -123,6 +241,357 @@ func TestUpdateSequence_Integration(t *testing.T) {
 	}
 }
 
+func TestCloneSequence_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Template Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: false,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+		{StepEmailSubject: "Subject 2", StepEmailBody: "Body 2", WaitDays: 2, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	clone := models.CloneSequenceRequest{
+		AccountID:       1,
+		SequenceID:      sequenceId,
+		TargetAccountID: 2,
+	}
+
+	accountId, clonedSequenceId, err := repo.CloneSequence(ctx, &clone)
+	if err != nil {
+		t.Fatalf("failed to clone sequence: %v", err)
+	}
+
+	if accountId != 2 || clonedSequenceId == sequenceId {
+		t.Fatalf("expected a new sequence in account 2, got %d in account %d", clonedSequenceId, accountId)
+	}
+
+	gotSequence, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 2, SequenceID: clonedSequenceId})
+	if err != nil {
+		t.Fatalf("failed to get cloned sequence: %v", err)
+	}
+
+	if gotSequence.SequenceName != "Template Sequence" || !gotSequence.SequenceOpenTrackingEnabled {
+		t.Fatalf("expected the clone to copy the sequence settings, got %+v", gotSequence)
+	}
+
+	if len(gotSteps) != 2 || gotSteps[0].StepEmailSubject != "Subject 1" || gotSteps[1].StepEmailSubject != "Subject 2" {
+		t.Fatalf("expected the clone to copy both steps in order, got %+v", gotSteps)
+	}
+}
+
+func TestDeleteSequence_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	delete := models.DeleteSequenceRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+	}
+
+	deletedSequenceId, err := repo.DeleteSequence(ctx, &delete)
+	if err != nil {
+		t.Fatalf("failed to delete sequence: %v", err)
+	}
+
+	if deletedSequenceId != sequenceId {
+		t.Fatalf("expected deletedSequenceId to be %d, got %d", sequenceId, deletedSequenceId)
+	}
+
+	var stepCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM steps WHERE sequence_id = $1", sequenceId).Scan(&stepCount); err != nil {
+		t.Fatalf("failed to count steps: %v", err)
+	}
+	if stepCount != 0 {
+		t.Fatalf("expected steps to be deleted with the sequence, got %d", stepCount)
+	}
+}
+
+func TestArchiveSequence_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	delete := models.DeleteSequenceRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+		Archive:    true,
+	}
+
+	if _, err := repo.DeleteSequence(ctx, &delete); err != nil {
+		t.Fatalf("failed to archive sequence: %v", err)
+	}
+
+	if _, _, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId}); err == nil {
+		t.Fatalf("expected archived sequence to be hidden")
+	}
+
+	var stepCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM steps WHERE sequence_id = $1", sequenceId).Scan(&stepCount); err != nil {
+		t.Fatalf("failed to count steps: %v", err)
+	}
+	if stepCount != 1 {
+		t.Fatalf("expected archived sequence to keep its steps, got %d", stepCount)
+	}
+}
+
+func TestAddStep_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	// First, add a sequence with two steps to insert between
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{
+			StepEmailSubject:  "Subject 1",
+			StepEmailBody:     "Body 1",
+			WaitDays:          1,
+			EligibleStartTime: 1706132001,
+			EligibleEndTime:   1706304801,
+		},
+		{
+			StepEmailSubject:  "Subject 3",
+			StepEmailBody:     "Body 3",
+			WaitDays:          3,
+			EligibleStartTime: 1706132001,
+			EligibleEndTime:   1706304801,
+		},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	add := models.AddStepRequest{
+		AccountID:         1,
+		SequenceID:        sequenceId,
+		Position:          2,
+		StepEmailSubject:  "Subject 2",
+		StepEmailBody:     "Body 2",
+		WaitDays:          2,
+		EligibleStartTime: 1706132001,
+		EligibleEndTime:   1706304801,
+	}
+
+	_, stepId, stepOrder, err := repo.AddStep(ctx, &add)
+	if err != nil {
+		t.Fatalf("failed to add step: %v", err)
+	}
+
+	if stepId != 3 || stepOrder != 2 {
+		t.Fatalf("expected stepId to be 3 and stepOrder to be 2, got %d and %d", stepId, stepOrder)
+	}
+
+	_, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	for i, subject := range []string{"Subject 1", "Subject 2", "Subject 3"} {
+		if gotSteps[i].StepEmailSubject != subject || gotSteps[i].StepOrder != i+1 {
+			t.Fatalf("expected step %d to be %q, got %+v", i+1, subject, gotSteps[i])
+		}
+	}
+
+	// A different account must not be able to add steps to the sequence
+	add.AccountID = 2
+	if _, _, _, err := repo.AddStep(ctx, &add); err == nil {
+		t.Fatalf("expected an error adding a step to another account's sequence")
+	}
+}
+
+func TestUpdateStepSchedule_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	// Only the scheduling fields are sent; subject and body are left untouched
+	update := models.UpdateStepRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+		StepID:     1,
+		WaitDays:   &[]int{4}[0],
+	}
+
+	if _, _, err := repo.UpdateStep(ctx, &update); err != nil {
+		t.Fatalf("failed to update step: %v", err)
+	}
+
+	_, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	if gotSteps[0].WaitDays != 4 || gotSteps[0].StepEmailSubject != "Subject 1" {
+		t.Fatalf("expected wait_days 4 and the original subject, got %+v", gotSteps[0])
+	}
+
+	// Moving the start past the stored end must be rejected
+	update = models.UpdateStepRequest{
+		AccountID:         1,
+		SequenceID:        sequenceId,
+		StepID:            1,
+		EligibleStartTime: &[]int64{1706304802}[0],
+	}
+
+	if _, _, err := repo.UpdateStep(ctx, &update); err == nil {
+		t.Fatalf("expected an error for an inverted eligible window")
+	}
+}
+
+func TestReorderSteps_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	// First, add a sequence with steps to reorder
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+		{StepEmailSubject: "Subject 2", StepEmailBody: "Body 2", WaitDays: 2, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+		{StepEmailSubject: "Subject 3", StepEmailBody: "Body 3", WaitDays: 3, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	reorder := models.ReorderStepsRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+		StepIDs:    []int64{1, 3, 2},
+	}
+
+	if _, err := repo.ReorderSteps(ctx, &reorder); err != nil {
+		t.Fatalf("failed to reorder steps: %v", err)
+	}
+
+	_, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	for i, stepId := range reorder.StepIDs {
+		if gotSteps[i].StepID != stepId || gotSteps[i].StepOrder != i+1 {
+			t.Fatalf("expected step %d at position %d, got %+v", stepId, i+1, gotSteps[i])
+		}
+	}
+
+	// A partial list must be rejected
+	reorder.StepIDs = []int64{2, 1}
+	if _, err := repo.ReorderSteps(ctx, &reorder); !errors.Is(err, persistence.ErrValidation) {
+		t.Fatalf("expected ErrValidation reordering with missing steps, got %v", err)
+	}
+}
+
+func TestRenameSequence_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: false,
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &[]models.Step{})
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	// Only the name is sent; the tracking flags are left untouched
+	update := models.UpdateSequenceRequest{
+		AccountID:    1,
+		SequenceID:   sequenceId,
+		SequenceName: &[]string{"Renamed Sequence"}[0],
+	}
+
+	if _, err := repo.UpdateSequence(ctx, &update); err != nil {
+		t.Fatalf("failed to update sequence: %v", err)
+	}
+
+	gotSequence, _, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	if gotSequence.SequenceName != "Renamed Sequence" || !gotSequence.SequenceOpenTrackingEnabled || gotSequence.SequenceClickTrackingEnabled || gotSequence.UpdatedAt == 0 {
+		t.Fatalf("expected only the name and updated_at to change, got %+v", gotSequence)
+	}
+}
+
 func TestUpdateStep_Integration(t *testing.T) {
 	setupTestDB()
 	repo := persistence.NewSequenceRepository(db)
@@ -154,8 +623,8 @@ func TestUpdateStep_Integration(t *testing.T) {
 		AccountID:        1,
 		SequenceID:       sequenceId,
 		StepID:           1,
-		StepEmailSubject: "Updated Subject",
-		StepEmailBody:    "Updated Body",
+		StepEmailSubject: &[]string{"Updated Subject"}[0],
+		StepEmailBody:    &[]string{"Updated Body"}[0],
 	}
 
 	updatedSequenceId, updatedStepId, err := repo.UpdateStep(ctx, &update)
@@ -168,6 +637,63 @@ func TestUpdateStep_Integration(t *testing.T) {
 	}
 }
 
+func TestUpdateStepVariants_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{
+		{
+			StepEmailSubject:  "Subject 1",
+			StepEmailBody:     "Body 1",
+			EligibleStartTime: 1706132001,
+			EligibleEndTime:   1706304801,
+			Variants: []models.StepVariant{
+				{StepEmailSubject: "Subject A", StepEmailBody: "Body A", Weight: 1},
+				{StepEmailSubject: "Subject B", StepEmailBody: "Body B", Weight: 1},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	_, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+	variants := gotSteps[0].Variants
+	if len(variants) != 2 || variants[0].StepEmailSubject != "Subject A" {
+		t.Fatalf("expected 2 variants, got %+v", variants)
+	}
+
+	// Keep A with a new weight, drop B and add C
+	variants = []models.StepVariant{
+		{VariantID: variants[0].VariantID, StepEmailSubject: "Subject A", StepEmailBody: "Body A", Weight: 3},
+		{StepEmailSubject: "Subject C", StepEmailBody: "Body C", Weight: 1},
+	}
+	update := models.UpdateStepRequest{AccountID: 1, SequenceID: sequenceId, StepID: gotSteps[0].StepID, Variants: &variants}
+	if _, _, err := repo.UpdateStep(ctx, &update); err != nil {
+		t.Fatalf("failed to update step: %v", err)
+	}
+
+	gotStep, err := repo.GetStep(ctx, 1, gotSteps[0].StepID)
+	if err != nil {
+		t.Fatalf("failed to get step: %v", err)
+	}
+	if len(gotStep.Variants) != 2 || gotStep.Variants[0].VariantID != variants[0].VariantID || gotStep.Variants[0].Weight != 3 || gotStep.Variants[1].StepEmailSubject != "Subject C" {
+		t.Fatalf("expected A to be kept and C added, got %+v", gotStep.Variants)
+	}
+
+	// Variants of other steps can't be taken over
+	variants = []models.StepVariant{{VariantID: 12345, StepEmailSubject: "Subject", StepEmailBody: "Body", Weight: 1}}
+	if _, _, err := repo.UpdateStep(ctx, &update); !errors.Is(err, persistence.ErrValidation) {
+		t.Fatalf("expected ErrValidation updating an unknown variant, got %v", err)
+	}
+}
+
 func TestDeleteStep_Integration(t *testing.T) {
 	setupTestDB()
 	repo := persistence.NewSequenceRepository(db)
@@ -209,4 +735,51 @@ func TestDeleteStep_Integration(t *testing.T) {
 	if deletedSequenceId != sequenceId || deletedStepId != 1 {
 		t.Fatalf("expected deletedSequenceId to be %d and deletedStepId to be 1, got %d and %d", sequenceId, deletedSequenceId, deletedStepId)
 	}
+
+	// Deleting it again, or from another account, matches no row
+	if _, _, err := repo.DeleteStep(ctx, &delete); !errors.Is(err, persistence.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting a missing step, got %v", err)
+	}
+}
+
+func TestDeleteStep_KeepsOrderContiguous_Integration(t *testing.T) {
+	setupTestDB()
+	repo := persistence.NewSequenceRepository(db)
+
+	sequence := models.Sequence{
+		AccountID:                    1,
+		SequenceName:                 "Test Sequence",
+		SequenceOpenTrackingEnabled:  true,
+		SequenceClickTrackingEnabled: true,
+	}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+		{StepEmailSubject: "Subject 2", StepEmailBody: "Body 2", WaitDays: 2, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+		{StepEmailSubject: "Subject 3", StepEmailBody: "Body 3", WaitDays: 3, EligibleStartTime: 1706132001, EligibleEndTime: 1706304801},
+	}
+
+	ctx := context.Background()
+	sequenceId, err := repo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+
+	delete := models.DeleteStepRequest{
+		AccountID:  1,
+		SequenceID: sequenceId,
+		StepID:     2,
+	}
+
+	if _, _, err := repo.DeleteStep(ctx, &delete); err != nil {
+		t.Fatalf("failed to delete step: %v", err)
+	}
+
+	_, gotSteps, err := repo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+
+	if len(gotSteps) != 2 || gotSteps[0].StepOrder != 1 || gotSteps[1].StepID != 3 || gotSteps[1].StepOrder != 2 {
+		t.Fatalf("expected steps 1 and 3 at positions 1 and 2, got %+v", gotSteps)
+	}
 }
@@ -0,0 +1,335 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/secrets"
+	"time"
+)
+
+// ProcessFunc handles a due send. Changes it makes to due.Enrollment and
+// due.Send are saved when it returns without an error. Setting due.Send's
+// status to sending claims the send for delivery; its outcome is then saved
+// with RecordSend.
+type ProcessFunc func(ctx context.Context, due *models.DueSend) error
+
+type SendRepository interface {
+	ProcessDueSend(ctx context.Context, now int64, process ProcessFunc) (found bool, err error)
+	RecordSend(ctx context.Context, due *models.DueSend) error
+	PostponeEnrollment(ctx context.Context, enrollmentId int64, nextSendAt int64) error
+}
+
+// EnrollmentError is returned by ProcessDueSend when a due enrollment was
+// found but couldn't be processed, so that it can be postponed instead of
+// holding up the enrollments due after it.
+type EnrollmentError struct {
+	EnrollmentID int64
+	Err          error
+}
+
+func (e *EnrollmentError) Error() string {
+	return fmt.Sprintf("enrollment %d: %v", e.EnrollmentID, e.Err)
+}
+
+func (e *EnrollmentError) Unwrap() error {
+	return e.Err
+}
+
+type sendRepository struct {
+	db        *sql.DB
+	sequences *sequenceRepository
+	cipher    *secrets.Cipher
+}
+
+// NewSendRepository decrypts the SMTP passwords of mailboxes with cipher.
+func NewSendRepository(db *sql.DB, cipher *secrets.Cipher) SendRepository {
+	return &sendRepository{
+		db:        db,
+		sequences: &sequenceRepository{db: db},
+		cipher:    cipher,
+	}
+}
+
+// ProcessDueSend locks the active enrollment that has been due the longest
+// and hands it to process, holding the lock until its changes are saved.
+// Enrollments locked by another replica are skipped. found is false when
+// nothing is due. Failures after the enrollment was found are returned as an
+// *EnrollmentError.
+func (r *sendRepository) ProcessDueSend(ctx context.Context, now int64, process ProcessFunc) (found bool, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	due, err := r.lockDueEnrollment(ctx, tx, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = r.processDueSend(ctx, tx, due, now, process)
+	if err != nil {
+		return true, &EnrollmentError{EnrollmentID: due.Enrollment.EnrollmentID, Err: err}
+	}
+
+	return true, nil
+}
+
+func (r *sendRepository) processDueSend(ctx context.Context, tx *sql.Tx, due *models.DueSend, now int64, process ProcessFunc) error {
+	err := r.loadDueSend(ctx, tx, due, now)
+	if err != nil {
+		return err
+	}
+
+	status, attempts := due.Send.Status, due.Send.Attempts
+	err = process(ctx, due)
+	if err != nil {
+		return err
+	}
+
+	err = r.saveDueSend(ctx, tx, due, due.Send.Status != status || due.Send.Attempts != attempts)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordSend saves the outcome of a send claimed by ProcessDueSend, along
+// with the enrollment's progress; an enrollment paused in the meantime stays
+// paused. A successful send can complete an A/B test of the step. Sends
+// whose outcome was recorded already fail with ErrConflict.
+func (r *sendRepository) RecordSend(ctx context.Context, due *models.DueSend) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	updatedAt := now.Unix()
+	sent := due.Send.Status == models.SendStatusSent
+	query := `UPDATE sends SET variant_id = $1, status = $2, error = $3, message_id = $4, sent_at = $5, updated_at = $6 WHERE send_id = $7 AND status = $8`
+	result, err := tx.ExecContext(ctx, query, nullIfZero(due.Send.VariantID), due.Send.Status, due.Send.Error, due.Send.MessageID, nullIfZero(due.Send.SentAt), updatedAt, due.Send.SendID, models.SendStatusSending)
+	if err != nil {
+		return translateError(err)
+	}
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if recorded == 0 {
+		return fmt.Errorf("%w: send %d is no longer being delivered", ErrConflict, due.Send.SendID)
+	}
+
+	if due.Send.MailboxID != 0 {
+		err = recordMailboxSend(ctx, tx, due.Send.MailboxID, sent, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sent && due.Step != nil {
+		err = r.promoteWinner(ctx, tx, due.Enrollment.AccountID, due.Step)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE enrollments SET status = CASE WHEN status = $1 THEN $2 ELSE status END, current_step = $3, current_step_id = $4, next_send_at = $5, updated_at = $6 WHERE enrollment_id = $7`
+	_, err = tx.ExecContext(ctx, query, models.EnrollmentStatusActive, due.Enrollment.Status, due.Enrollment.CurrentStep, nullIfZero(due.Enrollment.CurrentStepID), nullIfZero(due.Enrollment.NextSendAt), updatedAt, due.Enrollment.EnrollmentID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
+}
+
+// PostponeEnrollment moves an active enrollment's next send to nextSendAt.
+func (r *sendRepository) PostponeEnrollment(ctx context.Context, enrollmentId int64, nextSendAt int64) error {
+	query := `UPDATE enrollments SET next_send_at = $1, updated_at = $2 WHERE enrollment_id = $3 AND status = $4`
+	_, err := r.db.ExecContext(ctx, query, nextSendAt, time.Now().Unix(), enrollmentId, models.EnrollmentStatusActive)
+	return err
+}
+
+func (r *sendRepository) lockDueEnrollment(ctx context.Context, tx *sql.Tx, now int64) (*models.DueSend, error) {
+	query := `SELECT e.enrollment_id, e.account_id, e.sequence_id, e.contact_id, e.created_at, e.updated_at, e.status, e.current_step, e.current_step_id, e.next_send_at FROM enrollments e JOIN sequences s ON s.sequence_id = e.sequence_id WHERE e.status = $1 AND e.next_send_at <= $2 AND s.archived_at IS NULL ORDER BY e.next_send_at LIMIT 1 FOR UPDATE OF e SKIP LOCKED`
+	var due models.DueSend
+	var updatedAt sql.NullInt64
+	var currentStepId sql.NullInt64
+	var nextSendAt sql.NullInt64
+	err := tx.QueryRowContext(ctx, query, models.EnrollmentStatusActive, now).Scan(&due.Enrollment.EnrollmentID, &due.Enrollment.AccountID, &due.Enrollment.SequenceID, &due.Enrollment.ContactID, &due.Enrollment.CreatedAt, &updatedAt, &due.Enrollment.Status, &due.Enrollment.CurrentStep, &currentStepId, &nextSendAt)
+	if err != nil {
+		return nil, err
+	}
+	due.Enrollment.UpdatedAt = updatedAt.Int64
+	due.Enrollment.CurrentStepID = currentStepId.Int64
+	due.Enrollment.NextSendAt = nextSendAt.Int64
+
+	return &due, nil
+}
+
+// loadDueSend fills in everything else needed to deliver the locked
+// enrollment's current step.
+func (r *sendRepository) loadDueSend(ctx context.Context, tx *sql.Tx, due *models.DueSend, now int64) error {
+	sequence, err := r.sequences.getSequence(ctx, tx, due.Enrollment.AccountID, due.Enrollment.SequenceID)
+	if err != nil {
+		return err
+	}
+	due.Sequence = *sequence
+
+	mailbox, available, err := lockMailbox(ctx, tx, r.cipher, due.Enrollment.AccountID, due.Enrollment.SequenceID, time.Unix(now, 0))
+	if err != nil {
+		return err
+	}
+	due.Mailbox = mailbox
+	due.MailboxUnavailable = !available
+
+	due.Steps, err = r.sequences.getSteps(ctx, tx, due.Enrollment.AccountID, due.Enrollment.SequenceID)
+	if err != nil {
+		return err
+	}
+	for i := range due.Steps {
+		if due.Steps[i].StepID == due.Enrollment.CurrentStepID {
+			due.Step = &due.Steps[i]
+			break
+		}
+	}
+	due.SentStepIDs, err = r.getSentStepIds(ctx, tx, due.Enrollment.EnrollmentID)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT ` + contactColumns + ` FROM contacts WHERE account_id = $1 AND contact_id = $2`
+	contact, err := scanContact(tx.QueryRowContext(ctx, query, due.Enrollment.AccountID, due.Enrollment.ContactID))
+	if err != nil {
+		return translateError(err)
+	}
+	due.Contact = *contact
+
+	due.Send = models.Send{
+		AccountID:    due.Enrollment.AccountID,
+		EnrollmentID: due.Enrollment.EnrollmentID,
+	}
+	if due.Step != nil {
+		due.Send.StepID = due.Step.StepID
+		err = r.getSend(ctx, tx, &due.Send)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSentStepIds returns the steps the enrollment's contact was already sent.
+func (r *sendRepository) getSentStepIds(ctx context.Context, tx *sql.Tx, enrollmentId int64) (map[int64]bool, error) {
+	query := `SELECT step_id FROM sends WHERE enrollment_id = $1 AND status = $2`
+	rows, err := tx.QueryContext(ctx, query, enrollmentId, models.SendStatusSent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stepIds := map[int64]bool{}
+	for rows.Next() {
+		var stepId int64
+		if err := rows.Scan(&stepId); err != nil {
+			return nil, err
+		}
+		stepIds[stepId] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stepIds, nil
+}
+
+// getSend fills in earlier attempts at the send, if there were any.
+func (r *sendRepository) getSend(ctx context.Context, tx *sql.Tx, send *models.Send) error {
+	query := `SELECT send_id, mailbox_id, variant_id, created_at, updated_at, status, attempts, error, message_id, sent_at FROM sends WHERE enrollment_id = $1 AND step_id = $2`
+	var mailboxId sql.NullInt64
+	var variantId sql.NullInt64
+	var updatedAt sql.NullInt64
+	var sentAt sql.NullInt64
+	err := tx.QueryRowContext(ctx, query, send.EnrollmentID, send.StepID).Scan(&send.SendID, &mailboxId, &variantId, &send.CreatedAt, &updatedAt, &send.Status, &send.Attempts, &send.Error, &send.MessageID, &sentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	send.MailboxID = mailboxId.Int64
+	send.VariantID = variantId.Int64
+	send.UpdatedAt = updatedAt.Int64
+	send.SentAt = sentAt.Int64
+
+	return nil
+}
+
+// saveDueSend stores the enrollment's progress and, when it changed, the
+// send. A send claimed for delivery is counted against its mailbox's daily
+// limit right away, so that the mailbox is only locked while claiming it.
+func (r *sendRepository) saveDueSend(ctx context.Context, tx *sql.Tx, due *models.DueSend, changed bool) error {
+	now := time.Now()
+	updatedAt := now.Unix()
+	if changed {
+		if due.Send.Status == models.SendStatusSending && due.Mailbox != nil {
+			due.Send.MailboxID = due.Mailbox.MailboxID
+			err := reserveMailboxSend(ctx, tx, due.Mailbox.MailboxID, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		query := `INSERT INTO sends (account_id, enrollment_id, step_id, mailbox_id, variant_id, created_at, status, attempts, error, message_id, sent_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT (enrollment_id, step_id) DO UPDATE SET mailbox_id = EXCLUDED.mailbox_id, variant_id = EXCLUDED.variant_id, status = EXCLUDED.status, attempts = EXCLUDED.attempts, error = EXCLUDED.error, message_id = EXCLUDED.message_id, sent_at = EXCLUDED.sent_at, updated_at = $12 RETURNING send_id`
+		err := tx.QueryRowContext(ctx, query, due.Send.AccountID, due.Send.EnrollmentID, due.Send.StepID, nullIfZero(due.Send.MailboxID), nullIfZero(due.Send.VariantID), updatedAt, due.Send.Status, due.Send.Attempts, due.Send.Error, due.Send.MessageID, nullIfZero(due.Send.SentAt), updatedAt).Scan(&due.Send.SendID)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	query := `UPDATE enrollments SET status = $1, current_step = $2, current_step_id = $3, next_send_at = $4, updated_at = $5 WHERE enrollment_id = $6`
+	_, err := tx.ExecContext(ctx, query, due.Enrollment.Status, due.Enrollment.CurrentStep, nullIfZero(due.Enrollment.CurrentStepID), nullIfZero(due.Enrollment.NextSendAt), updatedAt, due.Enrollment.EnrollmentID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// promoteWinner promotes the best performing variant of step once its
+// variants were sent AutoPromoteAfter times in total.
+func (r *sendRepository) promoteWinner(ctx context.Context, tx *sql.Tx, accountId int64, step *models.Step) error {
+	if step.AutoPromoteAfter <= 0 || step.PromotedVariantID != 0 || len(step.Variants) < 2 {
+		return nil
+	}
+
+	stats, err := variantStats(ctx, tx, accountId, step.StepID)
+	if err != nil {
+		return err
+	}
+	sends := 0
+	for _, s := range stats {
+		sends += s.Sends
+	}
+	winner := models.Winner(stats)
+	if sends < step.AutoPromoteAfter || winner == nil {
+		return nil
+	}
+
+	query := `UPDATE steps SET promoted_variant_id = $1 WHERE step_id = $2 AND promoted_variant_id IS NULL`
+	_, err = tx.ExecContext(ctx, query, winner.VariantID, step.StepID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
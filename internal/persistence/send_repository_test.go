@@ -0,0 +1,232 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+func TestProcessDueSend_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	repo := persistence.NewSendRepository(db, testCipher)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{
+		{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1", WaitDays: 1},
+		{StepEmailSubject: "Subject 2", StepEmailBody: "Body 2", WaitDays: 2},
+	}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: "jane.doe@example.com", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	if _, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, []int64{contactId}); err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	// Nothing is due before the send time
+	found, err := repo.ProcessDueSend(ctx, 1706132000, func(ctx context.Context, due *models.DueSend) error {
+		t.Fatalf("unexpected due send %+v", due)
+		return nil
+	})
+	if err != nil || found {
+		t.Fatalf("expected nothing due, got %v and %v", found, err)
+	}
+
+	var claimed *models.DueSend
+	found, err = repo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+		if due.Step == nil || due.Step.StepOrder != 1 || due.Contact.Email != "jane.doe@example.com" || len(due.Steps) != 2 {
+			t.Fatalf("unexpected due send %+v", due)
+		}
+		due.Send.Attempts = 1
+		due.Send.Status = models.SendStatusSending
+		due.Enrollment.NextSendAt = 1706132601
+		claimed = due
+		return nil
+	})
+	if err != nil || !found {
+		t.Fatalf("expected a due send, got %v and %v", found, err)
+	}
+
+	// The claimed send isn't handed out again while it is delivered
+	found, err = repo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+		t.Fatalf("unexpected due send %+v", due)
+		return nil
+	})
+	if err != nil || found {
+		t.Fatalf("expected nothing due, got %v and %v", found, err)
+	}
+
+	claimed.Send.Status = models.SendStatusSent
+	claimed.Send.SentAt = 1706132001
+	claimed.Enrollment.CurrentStep = 2
+	claimed.Enrollment.CurrentStepID = 2
+	claimed.Enrollment.NextSendAt = 1706304801
+	if err := repo.RecordSend(ctx, claimed); err != nil {
+		t.Fatalf("failed to record send: %v", err)
+	}
+	if err := repo.RecordSend(ctx, claimed); !errors.Is(err, persistence.ErrConflict) {
+		t.Fatalf("expected the send to be recorded once, got %v", err)
+	}
+
+	// The enrollment was moved to its next step
+	found, err = repo.ProcessDueSend(ctx, 1706132601, func(ctx context.Context, due *models.DueSend) error {
+		t.Fatalf("unexpected due send %+v", due)
+		return nil
+	})
+	if err != nil || found {
+		t.Fatalf("expected nothing due, got %v and %v", found, err)
+	}
+	found, err = repo.ProcessDueSend(ctx, 1706304801, func(ctx context.Context, due *models.DueSend) error {
+		if due.Step == nil || due.Step.StepOrder != 2 || due.Send.Attempts != 0 || !due.SentStepIDs[1] {
+			t.Fatalf("unexpected due send %+v", due)
+		}
+		return nil
+	})
+	if err != nil || !found {
+		t.Fatalf("expected a due send, got %v and %v", found, err)
+	}
+}
+
+func TestPostponeEnrollment_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	repo := persistence.NewSendRepository(db, testCipher)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{{StepEmailSubject: "Subject 1", StepEmailBody: "Body 1"}}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: "jane.doe@example.com", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	enrollments, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, []int64{contactId})
+	if err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	found, err := repo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+		return errors.New("boom")
+	})
+	var enrollmentErr *persistence.EnrollmentError
+	if !found || !errors.As(err, &enrollmentErr) || enrollmentErr.EnrollmentID != enrollments[0].EnrollmentID {
+		t.Fatalf("expected an enrollment error, got %v and %v", found, err)
+	}
+
+	if err := repo.PostponeEnrollment(ctx, enrollments[0].EnrollmentID, 1706132601); err != nil {
+		t.Fatalf("failed to postpone enrollment: %v", err)
+	}
+	found, err = repo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+		t.Fatalf("unexpected due send %+v", due)
+		return nil
+	})
+	if err != nil || found {
+		t.Fatalf("expected nothing due, got %v and %v", found, err)
+	}
+}
+
+func TestProcessDueSend_PromotesWinner_Integration(t *testing.T) {
+	setupTestDB()
+	sequenceRepo := persistence.NewSequenceRepository(db)
+	contactRepo := persistence.NewContactRepository(db)
+	enrollmentRepo := persistence.NewEnrollmentRepository(db)
+	repo := persistence.NewSendRepository(db, testCipher)
+
+	ctx := context.Background()
+	sequence := models.Sequence{AccountID: 1, SequenceName: "Test Sequence"}
+	steps := []models.Step{
+		{
+			StepEmailSubject: "Subject",
+			StepEmailBody:    "Body",
+			AutoPromoteAfter: 2,
+			Variants: []models.StepVariant{
+				{StepEmailSubject: "Subject A", StepEmailBody: "Body A", Weight: 1},
+				{StepEmailSubject: "Subject B", StepEmailBody: "Body B", Weight: 1},
+			},
+		},
+	}
+	sequenceId, err := sequenceRepo.AddSequence(ctx, &sequence, &steps)
+	if err != nil {
+		t.Fatalf("failed to add sequence: %v", err)
+	}
+	_, gotSteps, err := sequenceRepo.GetSequence(ctx, &models.GetSequenceRequest{AccountID: 1, SequenceID: sequenceId})
+	if err != nil {
+		t.Fatalf("failed to get sequence: %v", err)
+	}
+	variants := gotSteps[0].Variants
+
+	var contactIds []int64
+	for _, email := range []string{"jane.doe@example.com", "jon.doe@example.com"} {
+		contactId, err := contactRepo.AddContact(ctx, &models.Contact{AccountID: 1, Email: email, Timezone: "UTC"})
+		if err != nil {
+			t.Fatalf("failed to add contact: %v", err)
+		}
+		contactIds = append(contactIds, contactId)
+	}
+	enrollment := models.Enrollment{AccountID: 1, SequenceID: sequenceId, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1706132001}
+	enrollments, _, err := enrollmentRepo.AddEnrollments(ctx, &enrollment, contactIds)
+	if err != nil {
+		t.Fatalf("failed to add enrollments: %v", err)
+	}
+
+	// The second contact gets variant B and replies to it.
+	query := `INSERT INTO events (account_id, sequence_id, enrollment_id, step_id, type, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	if _, err := db.Exec(query, 1, sequenceId, enrollments[1].EnrollmentID, gotSteps[0].StepID, models.EventTypeReply, 1706132002); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+
+	for range enrollments {
+		var claimed *models.DueSend
+		found, err := repo.ProcessDueSend(ctx, 1706132001, func(ctx context.Context, due *models.DueSend) error {
+			due.Send.Attempts = 1
+			due.Send.Status = models.SendStatusSending
+			claimed = due
+			return nil
+		})
+		if err != nil || !found {
+			t.Fatalf("expected a due send, got %v and %v", found, err)
+		}
+
+		claimed.Send.Status = models.SendStatusSent
+		claimed.Send.SentAt = 1706132001
+		claimed.Send.VariantID = variants[0].VariantID
+		if claimed.Enrollment.EnrollmentID == enrollments[1].EnrollmentID {
+			claimed.Send.VariantID = variants[1].VariantID
+		}
+		claimed.Enrollment.Status = models.EnrollmentStatusFinished
+		claimed.Enrollment.CurrentStepID = 0
+		claimed.Enrollment.NextSendAt = 0
+		if err := repo.RecordSend(ctx, claimed); err != nil {
+			t.Fatalf("failed to record send: %v", err)
+		}
+	}
+
+	step, stats, err := sequenceRepo.GetVariantStats(ctx, &models.GetVariantStatsRequest{AccountID: 1, StepID: gotSteps[0].StepID})
+	if err != nil {
+		t.Fatalf("failed to get variant stats: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Sends != 1 || stats[1].Sends != 1 || stats[1].Replies != 1 {
+		t.Fatalf("expected one send of each variant and a reply to B, got %+v", stats)
+	}
+	if step.PromotedVariantID != variants[1].VariantID {
+		t.Fatalf("expected variant %d to be promoted, got %d", variants[1].VariantID, step.PromotedVariantID)
+	}
+}
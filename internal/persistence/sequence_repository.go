@@ -3,14 +3,25 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
 	"salesforge-api/internal/models"
+	"strings"
 	"time"
 )
 
 type SequenceRepository interface {
 	AddSequence(ctx context.Context, sequence *models.Sequence, steps *[]models.Step) (sequenceId int64, err error)
+	AddStep(ctx context.Context, add *models.AddStepRequest) (sequenceId int64, stepId int64, stepOrder int, err error)
+	CloneSequence(ctx context.Context, clone *models.CloneSequenceRequest) (accountId int64, sequenceId int64, err error)
+	GetSequence(ctx context.Context, get *models.GetSequenceRequest) (sequence *models.Sequence, steps []models.Step, err error)
+	GetStep(ctx context.Context, accountId int64, stepId int64) (step *models.Step, err error)
+	GetVariantStats(ctx context.Context, get *models.GetVariantStatsRequest) (step *models.Step, stats []models.VariantStats, err error)
+	ListSequences(ctx context.Context, list *models.ListSequencesRequest) (sequences []models.Sequence, next *models.SequenceCursor, err error)
 	UpdateSequence(ctx context.Context, update *models.UpdateSequenceRequest) (sequenceId int64, err error)
+	DeleteSequence(ctx context.Context, delete *models.DeleteSequenceRequest) (sequenceId int64, err error)
 	UpdateStep(ctx context.Context, update *models.UpdateStepRequest) (sequenceId int64, stepId int64, err error)
+	ReorderSteps(ctx context.Context, reorder *models.ReorderStepsRequest) (sequenceId int64, err error)
 	DeleteStep(ctx context.Context, delete *models.DeleteStepRequest) (sequenceId int64, stepId int64, err error)
 }
 
@@ -36,7 +47,7 @@ func (r *sequenceRepository) AddSequence(ctx context.Context, sequence *models.S
 		return 0, err
 	}
 
-	err = r.addSteps(ctx, tx, accountId, sequenceId, steps)
+	_, err = r.addSteps(ctx, tx, accountId, sequenceId, 1, steps)
 	if err != nil {
 		return 0, err
 	}
@@ -54,25 +65,513 @@ func (r *sequenceRepository) addSequence(ctx context.Context, tx *sql.Tx, sequen
 	createdAt := time.Now().Unix()
 	err = tx.QueryRowContext(ctx, query, sequence.AccountID, createdAt, sequence.SequenceName, sequence.SequenceOpenTrackingEnabled, sequence.SequenceClickTrackingEnabled).Scan(&accountId, &sequenceId)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, translateError(err)
 	}
 
 	return accountId, sequenceId, nil
 }
 
-func (r *sequenceRepository) addSteps(ctx context.Context, tx *sql.Tx, accountId int64, sequenceId int64, steps *[]models.Step) error {
-	query := `INSERT INTO steps (account_id, sequence_id, created_at, step_email_subject, step_email_body, wait_days, eligible_start_time, eligible_end_time) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+// addSteps inserts steps at consecutive positions starting at position,
+// shifting the existing steps at or after it down to make room.
+func (r *sequenceRepository) addSteps(ctx context.Context, tx *sql.Tx, accountId int64, sequenceId int64, position int, steps *[]models.Step) (stepIds []int64, err error) {
+	if len(*steps) == 0 {
+		return nil, nil
+	}
+
+	shift := `UPDATE steps SET step_order = step_order + $1 WHERE sequence_id = $2 AND step_order >= $3`
+	_, err = tx.ExecContext(ctx, shift, len(*steps), sequenceId, position)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	query := `INSERT INTO steps (account_id, sequence_id, created_at, step_email_subject, step_email_body, wait_days, eligible_start_time, eligible_end_time, step_order, auto_promote_after) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING step_id`
+	createdAt := time.Now().Unix()
+	for i, step := range *steps {
+		var stepId int64
+		err = tx.QueryRowContext(ctx, query, accountId, sequenceId, createdAt, step.StepEmailSubject, step.StepEmailBody, step.WaitDays, step.EligibleStartTime, step.EligibleEndTime, position+i, step.AutoPromoteAfter).Scan(&stepId)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		stepIds = append(stepIds, stepId)
+
+		err = r.addVariants(ctx, tx, accountId, stepId, step.Variants, step.PromotedVariantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stepIds, nil
+}
+
+// addVariants inserts variants for a new step. When one of them is
+// promotedVariantId, which only happens when cloning, its copy is promoted.
+func (r *sequenceRepository) addVariants(ctx context.Context, tx *sql.Tx, accountId int64, stepId int64, variants []models.StepVariant, promotedVariantId int64) error {
+	query := `INSERT INTO step_variants (account_id, step_id, created_at, step_email_subject, step_email_body, weight) VALUES ($1, $2, $3, $4, $5, $6) RETURNING variant_id`
 	createdAt := time.Now().Unix()
-	for _, step := range *steps {
-		_, err := tx.ExecContext(ctx, query, accountId, sequenceId, createdAt, step.StepEmailSubject, step.StepEmailBody, step.WaitDays, step.EligibleStartTime, step.EligibleEndTime)
+	for _, variant := range variants {
+		var variantId int64
+		err := tx.QueryRowContext(ctx, query, accountId, stepId, createdAt, variant.StepEmailSubject, variant.StepEmailBody, variant.Weight).Scan(&variantId)
 		if err != nil {
-			return err
+			return translateError(err)
+		}
+		if promotedVariantId != 0 && variant.VariantID == promotedVariantId {
+			_, err = tx.ExecContext(ctx, `UPDATE steps SET promoted_variant_id = $1 WHERE step_id = $2`, variantId, stepId)
+			if err != nil {
+				return translateError(err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// replaceVariants makes variants the step's whole set: variants with an id
+// are updated in place, the rest are added and any others removed.
+func (r *sequenceRepository) replaceVariants(ctx context.Context, tx *sql.Tx, accountId int64, stepId int64, variants []models.StepVariant) error {
+	keep := []int64{}
+	for _, variant := range variants {
+		if variant.VariantID != 0 {
+			keep = append(keep, variant.VariantID)
+		}
+	}
+
+	query := `DELETE FROM step_variants WHERE step_id = $1 AND NOT (variant_id = ANY($2))`
+	_, err := tx.ExecContext(ctx, query, stepId, pq.Array(keep))
+	if err != nil {
+		return translateError(err)
+	}
+
+	var added []models.StepVariant
+	query = `UPDATE step_variants SET step_email_subject = $1, step_email_body = $2, weight = $3, updated_at = $4 WHERE step_id = $5 AND variant_id = $6`
+	updatedAt := time.Now().Unix()
+	for _, variant := range variants {
+		if variant.VariantID == 0 {
+			added = append(added, variant)
+			continue
+		}
+		res, err := tx.ExecContext(ctx, query, variant.StepEmailSubject, variant.StepEmailBody, variant.Weight, updatedAt, stepId, variant.VariantID)
+		if err != nil {
+			return translateError(err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return fmt.Errorf("%w: variant %d is not part of step %d", ErrValidation, variant.VariantID, stepId)
+		}
+	}
+
+	return r.addVariants(ctx, tx, accountId, stepId, added, 0)
+}
+
+func (r *sequenceRepository) AddStep(ctx context.Context, add *models.AddStepRequest) (sequenceId int64, stepId int64, stepOrder int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer tx.Rollback()
+
+	err = r.lockSequence(ctx, tx, add.AccountID, add.SequenceID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	stepCount, err := r.countSteps(ctx, tx, add.SequenceID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// Positions past the end, and the zero value, append the step.
+	stepOrder = add.Position
+	if stepOrder == 0 || stepOrder > stepCount+1 {
+		stepOrder = stepCount + 1
+	}
+
+	steps := []models.Step{add.ToStep()}
+	stepIds, err := r.addSteps(ctx, tx, add.AccountID, add.SequenceID, stepOrder, &steps)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return add.SequenceID, stepIds[0], stepOrder, nil
+}
+
+// lockSequence checks that the sequence belongs to the account and locks it,
+// serializing concurrent changes to its steps until the transaction ends.
+func (r *sequenceRepository) lockSequence(ctx context.Context, tx *sql.Tx, accountId int64, sequenceId int64) error {
+	query := `SELECT sequence_id FROM sequences WHERE account_id = $1 AND sequence_id = $2 AND archived_at IS NULL FOR UPDATE`
+	return translateError(tx.QueryRowContext(ctx, query, accountId, sequenceId).Scan(&sequenceId))
+}
+
+func (r *sequenceRepository) countSteps(ctx context.Context, tx *sql.Tx, sequenceId int64) (stepCount int, err error) {
+	query := `SELECT COUNT(*) FROM steps WHERE sequence_id = $1`
+	err = tx.QueryRowContext(ctx, query, sequenceId).Scan(&stepCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return stepCount, nil
+}
+
+func (r *sequenceRepository) CloneSequence(ctx context.Context, clone *models.CloneSequenceRequest) (accountId int64, sequenceId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	sequence, err := r.getSequence(ctx, tx, clone.AccountID, clone.SequenceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	steps, err := r.getSteps(ctx, tx, clone.AccountID, clone.SequenceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if clone.TargetAccountID != 0 {
+		sequence.AccountID = clone.TargetAccountID
+	}
+	if clone.SequenceName != nil {
+		sequence.SequenceName = *clone.SequenceName
+	}
+
+	accountId, sequenceId, err = r.addSequence(ctx, tx, sequence)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_, err = r.addSteps(ctx, tx, accountId, sequenceId, 1, &steps)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Mailboxes belong to the account, so pins only carry over within it.
+	if accountId == clone.AccountID {
+		query := `INSERT INTO sequence_mailboxes (sequence_id, mailbox_id) SELECT $1, mailbox_id FROM sequence_mailboxes WHERE sequence_id = $2`
+		_, err = tx.ExecContext(ctx, query, sequenceId, clone.SequenceID)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return accountId, sequenceId, nil
+}
+
+func (r *sequenceRepository) GetSequence(ctx context.Context, get *models.GetSequenceRequest) (sequence *models.Sequence, steps []models.Step, err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	sequence, err = r.getSequence(ctx, tx, get.AccountID, get.SequenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	steps, err = r.getSteps(ctx, tx, get.AccountID, get.SequenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sequence, steps, nil
+}
+
+func (r *sequenceRepository) getSequence(ctx context.Context, tx *sql.Tx, accountId int64, sequenceId int64) (*models.Sequence, error) {
+	query := `SELECT account_id, sequence_id, created_at, updated_at, sequence_name, sequence_open_tracking_enabled, sequence_click_tracking_enabled FROM sequences WHERE account_id = $1 AND sequence_id = $2 AND archived_at IS NULL`
+	var sequence models.Sequence
+	var updatedAt sql.NullInt64
+	err := tx.QueryRowContext(ctx, query, accountId, sequenceId).Scan(&sequence.AccountID, &sequence.SequenceID, &sequence.CreatedAt, &updatedAt, &sequence.SequenceName, &sequence.SequenceOpenTrackingEnabled, &sequence.SequenceClickTrackingEnabled)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	sequence.UpdatedAt = updatedAt.Int64
+
+	return &sequence, nil
+}
+
+func (r *sequenceRepository) getSteps(ctx context.Context, tx *sql.Tx, accountId int64, sequenceId int64) ([]models.Step, error) {
+	query := `SELECT ` + stepColumns + ` FROM steps st WHERE st.account_id = $1 AND st.sequence_id = $2 ORDER BY st.step_order`
+	rows, err := tx.QueryContext(ctx, query, accountId, sequenceId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	steps := []models.Step{}
+	for rows.Next() {
+		step, err := scanStep(rows)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, *step)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	variants, err := r.getVariants(ctx, tx, `st.sequence_id = $2`, accountId, sequenceId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range steps {
+		steps[i].Variants = variants[steps[i].StepID]
+		if steps[i].Variants == nil {
+			steps[i].Variants = []models.StepVariant{}
+		}
+	}
+
+	return steps, nil
+}
+
+// GetStep returns a step of a sequence that isn't archived.
+func (r *sequenceRepository) GetStep(ctx context.Context, accountId int64, stepId int64) (step *models.Step, err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	step, err = r.getStep(ctx, tx, accountId, stepId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+func (r *sequenceRepository) getStep(ctx context.Context, tx *sql.Tx, accountId int64, stepId int64) (*models.Step, error) {
+	query := `SELECT ` + stepColumns + ` FROM steps st JOIN sequences s ON s.sequence_id = st.sequence_id WHERE st.account_id = $1 AND st.step_id = $2 AND s.archived_at IS NULL`
+	step, err := scanStep(tx.QueryRowContext(ctx, query, accountId, stepId))
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	variants, err := r.getVariants(ctx, tx, `v.step_id = $2`, accountId, stepId)
+	if err != nil {
+		return nil, err
+	}
+	step.Variants = variants[step.StepID]
+	if step.Variants == nil {
+		step.Variants = []models.StepVariant{}
+	}
+
+	return step, nil
+}
+
+// getVariants returns the variants matching cond, by step. The first
+// argument is always the account.
+func (r *sequenceRepository) getVariants(ctx context.Context, tx *sql.Tx, cond string, args ...interface{}) (map[int64][]models.StepVariant, error) {
+	query := `SELECT v.step_id, v.variant_id, v.step_email_subject, v.step_email_body, v.weight FROM step_variants v JOIN steps st ON st.step_id = v.step_id WHERE v.account_id = $1 AND ` + cond + ` ORDER BY v.variant_id`
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := map[int64][]models.StepVariant{}
+	for rows.Next() {
+		var stepId int64
+		var variant models.StepVariant
+		err = rows.Scan(&stepId, &variant.VariantID, &variant.StepEmailSubject, &variant.StepEmailBody, &variant.Weight)
+		if err != nil {
+			return nil, err
+		}
+		variants[stepId] = append(variants[stepId], variant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+const stepColumns = `st.step_id, st.sequence_id, st.created_at, st.updated_at, st.step_email_subject, st.step_email_body, st.wait_days, st.eligible_start_time, st.eligible_end_time, st.step_order, st.auto_promote_after, st.promoted_variant_id`
+
+func scanStep(row rowScanner) (*models.Step, error) {
+	var step models.Step
+	var updatedAt sql.NullInt64
+	var promotedVariantId sql.NullInt64
+	err := row.Scan(&step.StepID, &step.SequenceID, &step.CreatedAt, &updatedAt, &step.StepEmailSubject, &step.StepEmailBody, &step.WaitDays, &step.EligibleStartTime, &step.EligibleEndTime, &step.StepOrder, &step.AutoPromoteAfter, &promotedVariantId)
+	if err != nil {
+		return nil, err
+	}
+	step.UpdatedAt = updatedAt.Int64
+	step.PromotedVariantID = promotedVariantId.Int64
+
+	return &step, nil
+}
+
+// GetVariantStats returns a step with the stats of each of its variants.
+func (r *sequenceRepository) GetVariantStats(ctx context.Context, get *models.GetVariantStatsRequest) (step *models.Step, stats []models.VariantStats, err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	step, err = r.getStep(ctx, tx, get.AccountID, get.StepID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats, err = variantStats(ctx, tx, get.AccountID, get.StepID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return step, stats, nil
+}
+
+// variantStats counts the successful sends of each variant of a step, and
+// the recipients of those sends that opened, clicked or replied.
+func variantStats(ctx context.Context, tx *sql.Tx, accountId int64, stepId int64) ([]models.VariantStats, error) {
+	query := `SELECT v.variant_id, v.step_email_subject, v.weight,
+		COUNT(DISTINCT se.send_id),
+		COUNT(DISTINCT ev.enrollment_id) FILTER (WHERE ev.type = $3),
+		COUNT(DISTINCT ev.enrollment_id) FILTER (WHERE ev.type = $4),
+		COUNT(DISTINCT ev.enrollment_id) FILTER (WHERE ev.type = $5)
+		FROM step_variants v
+		LEFT JOIN sends se ON se.variant_id = v.variant_id AND se.status = $6
+		LEFT JOIN events ev ON ev.enrollment_id = se.enrollment_id AND ev.step_id = se.step_id
+		WHERE v.account_id = $1 AND v.step_id = $2
+		GROUP BY v.variant_id ORDER BY v.variant_id`
+	rows, err := tx.QueryContext(ctx, query, accountId, stepId, models.EventTypeOpen, models.EventTypeClick, models.EventTypeReply, models.SendStatusSent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.VariantStats{}
+	for rows.Next() {
+		var s models.VariantStats
+		err = rows.Scan(&s.VariantID, &s.StepEmailSubject, &s.Weight, &s.Sends, &s.Opens, &s.Clicks, &s.Replies)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// likeEscaper escapes LIKE wildcards so user input only matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *sequenceRepository) ListSequences(ctx context.Context, list *models.ListSequencesRequest) (sequences []models.Sequence, next *models.SequenceCursor, err error) {
+	conditions := []string{"account_id = $1", "archived_at IS NULL"}
+	args := []interface{}{list.AccountID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if list.Name != "" {
+		conditions = append(conditions, "sequence_name ILIKE '%' || "+arg(likeEscaper.Replace(list.Name))+" || '%'")
+	}
+	if list.OpenTrackingEnabled != nil {
+		conditions = append(conditions, "sequence_open_tracking_enabled = "+arg(*list.OpenTrackingEnabled))
+	}
+	if list.ClickTrackingEnabled != nil {
+		conditions = append(conditions, "sequence_click_tracking_enabled = "+arg(*list.ClickTrackingEnabled))
+	}
+	if list.CreatedFrom > 0 {
+		conditions = append(conditions, "created_at >= "+arg(list.CreatedFrom))
+	}
+	if list.CreatedTo > 0 {
+		conditions = append(conditions, "created_at <= "+arg(list.CreatedTo))
+	}
+	if list.UpdatedFrom > 0 {
+		conditions = append(conditions, "updated_at >= "+arg(list.UpdatedFrom))
+	}
+	if list.UpdatedTo > 0 {
+		conditions = append(conditions, "updated_at <= "+arg(list.UpdatedTo))
+	}
+
+	comparison, direction := ">", "ASC"
+	if list.SortOrder == models.SortOrderDesc {
+		comparison, direction = "<", "DESC"
+	}
+
+	orderBy := "sequence_id " + direction
+	if list.SortBy == models.SequenceSortByCreatedAt {
+		orderBy = "created_at " + direction + ", " + orderBy
+	}
+
+	if list.Cursor != nil {
+		if list.SortBy == models.SequenceSortByCreatedAt {
+			conditions = append(conditions, "(created_at, sequence_id) "+comparison+" ("+arg(list.Cursor.CreatedAt)+", "+arg(list.Cursor.SequenceID)+")")
+		} else {
+			conditions = append(conditions, "sequence_id "+comparison+" "+arg(list.Cursor.SequenceID))
+		}
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	query := `SELECT account_id, sequence_id, created_at, updated_at, sequence_name, sequence_open_tracking_enabled, sequence_click_tracking_enabled FROM sequences WHERE ` +
+		strings.Join(conditions, " AND ") + ` ORDER BY ` + orderBy + ` LIMIT ` + arg(list.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+	defer rows.Close()
+
+	sequences = []models.Sequence{}
+	for rows.Next() {
+		var sequence models.Sequence
+		var updatedAt sql.NullInt64
+		err = rows.Scan(&sequence.AccountID, &sequence.SequenceID, &sequence.CreatedAt, &updatedAt, &sequence.SequenceName, &sequence.SequenceOpenTrackingEnabled, &sequence.SequenceClickTrackingEnabled)
+		if err != nil {
+			return nil, nil, translateError(err)
+		}
+		sequence.UpdatedAt = updatedAt.Int64
+		sequences = append(sequences, sequence)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	if len(sequences) > list.Limit {
+		sequences = sequences[:list.Limit]
+		last := sequences[len(sequences)-1]
+		next = &models.SequenceCursor{
+			SortBy:     list.SortBy,
+			SortOrder:  list.SortOrder,
+			CreatedAt:  last.CreatedAt,
+			SequenceID: last.SequenceID,
+		}
+	}
+
+	return sequences, next, nil
+}
+
 func (r *sequenceRepository) UpdateSequence(ctx context.Context, update *models.UpdateSequenceRequest) (sequenceId int64, err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -94,9 +593,44 @@ func (r *sequenceRepository) UpdateSequence(ctx context.Context, update *models.
 }
 
 func (r *sequenceRepository) updateSequence(ctx context.Context, tx *sql.Tx, update *models.UpdateSequenceRequest) (sequenceId int64, err error) {
-	query := `UPDATE sequences SET sequence_open_tracking_enabled = $1, sequence_click_tracking_enabled = $2, updated_at = $3 WHERE account_id = $4 AND sequence_id = $5 RETURNING sequence_id`
-	updatedAt := time.Now().Unix()
-	err = tx.QueryRowContext(ctx, query, update.SequenceOpenTrackingEnabled, update.SequenceClickTrackingEnabled, updatedAt, update.AccountID, update.SequenceID).Scan(&sequenceId)
+	u := &updateBuilder{}
+	if update.SequenceName != nil {
+		u.set("sequence_name", *update.SequenceName)
+	}
+	if update.SequenceOpenTrackingEnabled != nil {
+		u.set("sequence_open_tracking_enabled", *update.SequenceOpenTrackingEnabled)
+	}
+	if update.SequenceClickTrackingEnabled != nil {
+		u.set("sequence_click_tracking_enabled", *update.SequenceClickTrackingEnabled)
+	}
+	u.set("updated_at", time.Now().Unix())
+
+	query := `UPDATE sequences SET ` + u.clause() + ` WHERE account_id = ` + u.arg(update.AccountID) + ` AND sequence_id = ` + u.arg(update.SequenceID) + ` AND archived_at IS NULL RETURNING sequence_id`
+	err = tx.QueryRowContext(ctx, query, u.args...).Scan(&sequenceId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return sequenceId, nil
+}
+
+func (r *sequenceRepository) DeleteSequence(ctx context.Context, delete *models.DeleteSequenceRequest) (sequenceId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if delete.Archive {
+		sequenceId, err = r.archiveSequence(ctx, tx, delete)
+	} else {
+		sequenceId, err = r.deleteSequence(ctx, tx, delete)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return 0, err
 	}
@@ -104,6 +638,31 @@ func (r *sequenceRepository) updateSequence(ctx context.Context, tx *sql.Tx, upd
 	return sequenceId, nil
 }
 
+// deleteSequence removes the sequence; its steps are removed by the
+// ON DELETE CASCADE of the steps foreign key.
+func (r *sequenceRepository) deleteSequence(ctx context.Context, tx *sql.Tx, delete *models.DeleteSequenceRequest) (sequenceId int64, err error) {
+	query := `DELETE FROM sequences WHERE account_id = $1 AND sequence_id = $2 RETURNING sequence_id`
+	err = tx.QueryRowContext(ctx, query, delete.AccountID, delete.SequenceID).Scan(&sequenceId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return sequenceId, nil
+}
+
+// archiveSequence hides the sequence from reads and changes while keeping it
+// and its steps in the database.
+func (r *sequenceRepository) archiveSequence(ctx context.Context, tx *sql.Tx, delete *models.DeleteSequenceRequest) (sequenceId int64, err error) {
+	query := `UPDATE sequences SET archived_at = $1, updated_at = $1 WHERE account_id = $2 AND sequence_id = $3 AND archived_at IS NULL RETURNING sequence_id`
+	archivedAt := time.Now().Unix()
+	err = tx.QueryRowContext(ctx, query, archivedAt, delete.AccountID, delete.SequenceID).Scan(&sequenceId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return sequenceId, nil
+}
+
 func (r *sequenceRepository) UpdateStep(ctx context.Context, update *models.UpdateStepRequest) (sequenceId int64, stepId int64, err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -111,6 +670,11 @@ func (r *sequenceRepository) UpdateStep(ctx context.Context, update *models.Upda
 	}
 	defer tx.Rollback()
 
+	err = r.lockSequence(ctx, tx, update.AccountID, update.SequenceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	sequenceId, stepId, err = r.updateStep(ctx, tx, update)
 	if err != nil {
 		return 0, 0, err
@@ -125,16 +689,141 @@ func (r *sequenceRepository) UpdateStep(ctx context.Context, update *models.Upda
 }
 
 func (r *sequenceRepository) updateStep(ctx context.Context, tx *sql.Tx, update *models.UpdateStepRequest) (sequenceId int64, stepId int64, err error) {
-	query := `UPDATE steps SET step_email_subject = $1, step_email_body = $2, updated_at = $3 WHERE account_id = $4 AND sequence_id = $5 AND step_id = $6 RETURNING sequence_id, step_id`
-	updatedAt := time.Now().Unix()
-	err = tx.QueryRowContext(ctx, query, update.StepEmailSubject, update.StepEmailBody, updatedAt, update.AccountID, update.SequenceID, update.StepID).Scan(&sequenceId, &stepId)
+	u := &updateBuilder{}
+	if update.StepEmailSubject != nil {
+		u.set("step_email_subject", *update.StepEmailSubject)
+	}
+	if update.StepEmailBody != nil {
+		u.set("step_email_body", *update.StepEmailBody)
+	}
+	if update.WaitDays != nil {
+		u.set("wait_days", *update.WaitDays)
+	}
+	if update.EligibleStartTime != nil {
+		u.set("eligible_start_time", *update.EligibleStartTime)
+	}
+	if update.EligibleEndTime != nil {
+		u.set("eligible_end_time", *update.EligibleEndTime)
+	}
+	if update.AutoPromoteAfter != nil {
+		u.set("auto_promote_after", *update.AutoPromoteAfter)
+	}
+	// New variants restart the test.
+	if update.Variants != nil {
+		u.set("promoted_variant_id", nil)
+	}
+	u.set("updated_at", time.Now().Unix())
+
+	query := `UPDATE steps SET ` + u.clause() + ` WHERE account_id = ` + u.arg(update.AccountID) + ` AND sequence_id = ` + u.arg(update.SequenceID) + ` AND step_id = ` + u.arg(update.StepID) + ` RETURNING sequence_id, step_id, eligible_start_time, eligible_end_time`
+	var eligibleStartTime, eligibleEndTime int64
+	err = tx.QueryRowContext(ctx, query, u.args...).Scan(&sequenceId, &stepId, &eligibleStartTime, &eligibleEndTime)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, translateError(err)
+	}
+
+	// Only one end of the window may have been sent, so the result is checked
+	// as a whole; returning the error rolls the update back.
+	if eligibleStartTime >= eligibleEndTime {
+		return 0, 0, fmt.Errorf("%w: eligible_start_time %d is not before eligible_end_time %d", ErrValidation, eligibleStartTime, eligibleEndTime)
+	}
+
+	if update.Variants != nil {
+		err = r.replaceVariants(ctx, tx, update.AccountID, stepId, *update.Variants)
+		if err != nil {
+			return 0, 0, err
+		}
 	}
 
 	return sequenceId, stepId, nil
 }
 
+// updateBuilder collects the SET assignments of a partial update together
+// with their positional arguments.
+type updateBuilder struct {
+	sets []string
+	args []interface{}
+}
+
+func (u *updateBuilder) arg(v interface{}) string {
+	u.args = append(u.args, v)
+	return fmt.Sprintf("$%d", len(u.args))
+}
+
+func (u *updateBuilder) set(column string, v interface{}) {
+	u.sets = append(u.sets, column+" = "+u.arg(v))
+}
+
+func (u *updateBuilder) clause() string {
+	return strings.Join(u.sets, ", ")
+}
+
+func (r *sequenceRepository) ReorderSteps(ctx context.Context, reorder *models.ReorderStepsRequest) (sequenceId int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = r.lockSequence(ctx, tx, reorder.AccountID, reorder.SequenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.reorderSteps(ctx, tx, reorder)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return reorder.SequenceID, nil
+}
+
+// reorderSteps assigns positions 1..n following the given step IDs, which
+// must be exactly the steps of the sequence.
+func (r *sequenceRepository) reorderSteps(ctx context.Context, tx *sql.Tx, reorder *models.ReorderStepsRequest) error {
+	rows, err := tx.QueryContext(ctx, `SELECT step_id FROM steps WHERE sequence_id = $1`, reorder.SequenceID)
+	if err != nil {
+		return translateError(err)
+	}
+	defer rows.Close()
+
+	current := make(map[int64]bool)
+	for rows.Next() {
+		var stepId int64
+		if err = rows.Scan(&stepId); err != nil {
+			return translateError(err)
+		}
+		current[stepId] = true
+	}
+	if err = rows.Err(); err != nil {
+		return translateError(err)
+	}
+
+	if len(current) != len(reorder.StepIDs) {
+		return fmt.Errorf("%w: expected %d step ids, got %d", ErrValidation, len(current), len(reorder.StepIDs))
+	}
+	for _, stepId := range reorder.StepIDs {
+		if !current[stepId] {
+			return fmt.Errorf("%w: step %d is not part of sequence %d", ErrValidation, stepId, reorder.SequenceID)
+		}
+	}
+
+	query := `UPDATE steps SET step_order = $1, updated_at = $2 WHERE sequence_id = $3 AND step_id = $4 AND step_order <> $1`
+	updatedAt := time.Now().Unix()
+	for i, stepId := range reorder.StepIDs {
+		_, err = tx.ExecContext(ctx, query, i+1, updatedAt, reorder.SequenceID, stepId)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	return nil
+}
+
 func (r *sequenceRepository) DeleteStep(ctx context.Context, delete *models.DeleteStepRequest) (sequenceId int64, stepId int64, err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -142,6 +831,11 @@ func (r *sequenceRepository) DeleteStep(ctx context.Context, delete *models.Dele
 	}
 	defer tx.Rollback()
 
+	err = r.lockSequence(ctx, tx, delete.AccountID, delete.SequenceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	sequenceId, stepId, err = r.deleteStep(ctx, tx, delete)
 	if err != nil {
 		return 0, 0, err
@@ -155,11 +849,20 @@ func (r *sequenceRepository) DeleteStep(ctx context.Context, delete *models.Dele
 	return sequenceId, stepId, nil
 }
 
+// deleteStep removes the step and moves the steps after it up by one, so
+// positions stay contiguous.
 func (r *sequenceRepository) deleteStep(ctx context.Context, tx *sql.Tx, delete *models.DeleteStepRequest) (sequenceId int64, stepId int64, err error) {
-	query := `DELETE FROM steps WHERE account_id = $1 AND sequence_id = $2 AND step_id = $3 RETURNING sequence_id, step_id`
-	err = tx.QueryRowContext(ctx, query, delete.AccountID, delete.SequenceID, delete.StepID).Scan(&sequenceId, &stepId)
+	query := `DELETE FROM steps WHERE account_id = $1 AND sequence_id = $2 AND step_id = $3 RETURNING sequence_id, step_id, step_order`
+	var stepOrder int
+	err = tx.QueryRowContext(ctx, query, delete.AccountID, delete.SequenceID, delete.StepID).Scan(&sequenceId, &stepId, &stepOrder)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, translateError(err)
+	}
+
+	shift := `UPDATE steps SET step_order = step_order - 1 WHERE sequence_id = $1 AND step_order > $2`
+	_, err = tx.ExecContext(ctx, shift, sequenceId, stepOrder)
+	if err != nil {
+		return 0, 0, translateError(err)
 	}
 
 	return sequenceId, stepId, nil
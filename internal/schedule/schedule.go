@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"salesforge-api/internal/models"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// SendTime is when step becomes due counting its WaitDays from base, moved
+// forward to the start of its eligible window. ok is false when the window
+// closes before the step is due. An end time of zero leaves the window open.
+func SendTime(step models.Step, base time.Time) (sendAt time.Time, ok bool) {
+	sendAt = base.Add(time.Duration(step.WaitDays) * day)
+	if start := time.Unix(step.EligibleStartTime, 0); sendAt.Before(start) {
+		sendAt = start
+	}
+	if step.EligibleEndTime > 0 && sendAt.After(time.Unix(step.EligibleEndTime, 0)) {
+		return time.Time{}, false
+	}
+	return sendAt, true
+}
+
+// Next finds the first step at or after position that can still be sent,
+// skipping steps whose eligible window has closed. Steps must be sorted by
+// StepOrder. ok is false when no step is left.
+func Next(steps []models.Step, position int, base time.Time) (step *models.Step, sendAt time.Time, ok bool) {
+	for i := range steps {
+		if steps[i].StepOrder < position {
+			continue
+		}
+		if sendAt, ok := SendTime(steps[i], base); ok {
+			return &steps[i], sendAt, true
+		}
+	}
+	return nil, time.Time{}, false
+}
+
+// Resume reschedules an enrollment carrying on after a pause: its current
+// step is due WaitDays after now, as if it had just been scheduled. When the
+// step was removed or its window has closed, the enrollment moves on to the
+// next step that can still be sent, or finishes when there is none.
+func Resume(enrollment *models.Enrollment, steps []models.Step, now time.Time) {
+	position := enrollment.CurrentStep
+	for i := range steps {
+		if steps[i].StepID != enrollment.CurrentStepID || enrollment.CurrentStepID == 0 {
+			continue
+		}
+		if sendAt, ok := SendTime(steps[i], now); ok {
+			enrollment.NextSendAt = sendAt.Unix()
+			return
+		}
+		position = steps[i].StepOrder + 1
+	}
+
+	step, sendAt, ok := Next(steps, position, now)
+	if !ok {
+		enrollment.Status = models.EnrollmentStatusFinished
+		enrollment.CurrentStep = 0
+		enrollment.CurrentStepID = 0
+		enrollment.NextSendAt = 0
+		return
+	}
+	enrollment.CurrentStep = step.StepOrder
+	enrollment.CurrentStepID = step.StepID
+	enrollment.NextSendAt = sendAt.Unix()
+}
@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/assert"
+	"salesforge-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestSendTime(t *testing.T) {
+	base := time.Unix(1737000000, 0)
+
+	tests := []struct {
+		name   string
+		step   models.Step
+		sendAt time.Time
+		ok     bool
+	}{
+		{
+			name:   "due inside the window",
+			step:   models.Step{WaitDays: 1, EligibleStartTime: 1736000000, EligibleEndTime: 1738000000},
+			sendAt: base.Add(24 * time.Hour),
+			ok:     true,
+		},
+		{
+			name:   "due before the window opens",
+			step:   models.Step{WaitDays: 0, EligibleStartTime: 1737500000, EligibleEndTime: 1738000000},
+			sendAt: time.Unix(1737500000, 0),
+			ok:     true,
+		},
+		{
+			name: "window closed before due",
+			step: models.Step{WaitDays: 2, EligibleStartTime: 1736000000, EligibleEndTime: 1737100000},
+			ok:   false,
+		},
+		{
+			name:   "open ended window",
+			step:   models.Step{WaitDays: 3},
+			sendAt: base.Add(72 * time.Hour),
+			ok:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sendAt, ok := SendTime(tt.step, base)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.True(t, tt.sendAt.Equal(sendAt), "expected %v, got %v", tt.sendAt, sendAt)
+			}
+		})
+	}
+}
+
+func TestNext_SkipsClosedWindows(t *testing.T) {
+	base := time.Unix(1737000000, 0)
+	steps := []models.Step{
+		{StepID: 10, StepOrder: 1, WaitDays: 0, EligibleStartTime: 1736000000, EligibleEndTime: 1738000000},
+		{StepID: 11, StepOrder: 2, WaitDays: 0, EligibleStartTime: 1736000000, EligibleEndTime: 1736500000},
+		{StepID: 12, StepOrder: 3, WaitDays: 1, EligibleStartTime: 1736000000, EligibleEndTime: 1738000000},
+	}
+
+	step, sendAt, ok := Next(steps, 2, base)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), step.StepID)
+	assert.True(t, base.Add(24*time.Hour).Equal(sendAt))
+
+	_, _, ok = Next(steps, 4, base)
+	assert.False(t, ok)
+}
+
+func TestResume(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	steps := []models.Step{
+		{StepID: 10, StepOrder: 1, WaitDays: 2},
+		{StepID: 11, StepOrder: 2, WaitDays: 1, EligibleStartTime: 1736000000, EligibleEndTime: 1736500000},
+		{StepID: 12, StepOrder: 3, WaitDays: 1},
+	}
+
+	// The current step waits again from now, not from when it was scheduled
+	enrollment := models.Enrollment{Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 10, NextSendAt: 1736000000}
+	Resume(&enrollment, steps, now)
+	assert.Equal(t, int64(10), enrollment.CurrentStepID)
+	assert.Equal(t, now.Add(48*time.Hour).Unix(), enrollment.NextSendAt)
+
+	// Its window closed during the pause
+	enrollment = models.Enrollment{Status: models.EnrollmentStatusActive, CurrentStep: 2, CurrentStepID: 11, NextSendAt: 1736000000}
+	Resume(&enrollment, steps, now)
+	assert.Equal(t, 3, enrollment.CurrentStep)
+	assert.Equal(t, int64(12), enrollment.CurrentStepID)
+	assert.Equal(t, now.Add(24*time.Hour).Unix(), enrollment.NextSendAt)
+
+	// It was removed; the step after it moved up into its position
+	enrollment = models.Enrollment{Status: models.EnrollmentStatusActive, CurrentStep: 3, NextSendAt: 1736000000}
+	Resume(&enrollment, steps, now)
+	assert.Equal(t, int64(12), enrollment.CurrentStepID)
+
+	// Nothing is left to send
+	enrollment = models.Enrollment{Status: models.EnrollmentStatusActive, CurrentStep: 4, NextSendAt: 1736000000}
+	Resume(&enrollment, steps, now)
+	assert.Equal(t, models.EnrollmentStatusFinished, enrollment.Status)
+	assert.Zero(t, enrollment.CurrentStepID)
+	assert.Zero(t, enrollment.NextSendAt)
+}
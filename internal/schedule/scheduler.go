@@ -0,0 +1,235 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"salesforge-api/internal/config"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Delivery sends the current step of a due enrollment to its contact.
+type Delivery interface {
+	Deliver(ctx context.Context, due *models.DueSend) error
+}
+
+// Scheduler periodically advances active enrollments whose next step is due,
+// handing each one to a Delivery. Every replica can run one; an enrollment
+// is only ever processed by a single replica at a time.
+type Scheduler struct {
+	conf     config.SchedulerConfig
+	sendRepo persistence.SendRepository
+	delivery Delivery
+	logger   *zap.Logger
+	now      func() time.Time
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewScheduler(conf config.SchedulerConfig, sendRepo persistence.SendRepository, delivery Delivery, l *zap.Logger) *Scheduler {
+	return &Scheduler{
+		conf:     conf,
+		sendRepo: sendRepo,
+		delivery: delivery,
+		logger:   l,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Shutdown is called.
+// Only the first call starts it.
+func (s *Scheduler) Start() {
+	if !s.started.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(time.Duration(s.conf.IntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			s.Tick(context.Background())
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the scheduler once the send in progress is saved, or
+// returns the context's error if that takes too long. Sends are never
+// interrupted halfway so a contact doesn't get the same step twice. It can
+// be called more than once, and returns right away if Start never was.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	if !s.started.Load() {
+		return nil
+	}
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Tick processes up to BatchSize due sends and returns how many it handled.
+// Enrollments that fail are retried after the retry delay, so that they
+// don't hold up the rest.
+func (s *Scheduler) Tick(ctx context.Context) (processed int) {
+	for processed < s.conf.BatchSize {
+		select {
+		case <-s.stop:
+			return processed
+		default:
+		}
+
+		var claimed *models.DueSend
+		found, err := s.sendRepo.ProcessDueSend(ctx, s.now().Unix(), func(ctx context.Context, due *models.DueSend) error {
+			if s.claim(due) {
+				claimed = due
+			}
+			return nil
+		})
+		var enrollmentErr *persistence.EnrollmentError
+		if errors.As(err, &enrollmentErr) {
+			s.logger.Error("failed to process enrollment", zap.Int64("enrollment_id", enrollmentErr.EnrollmentID), zap.Error(enrollmentErr.Err))
+			nextSendAt := s.now().Add(time.Duration(s.conf.RetryDelaySeconds) * time.Second).Unix()
+			if err := s.sendRepo.PostponeEnrollment(ctx, enrollmentErr.EnrollmentID, nextSendAt); err != nil {
+				s.logger.Error("failed to postpone enrollment", zap.Int64("enrollment_id", enrollmentErr.EnrollmentID), zap.Error(err))
+				return processed
+			}
+			processed++
+			continue
+		}
+		if err != nil {
+			s.logger.Error("failed to process due send", zap.Error(err))
+			return processed
+		}
+		if !found {
+			return processed
+		}
+		if claimed != nil {
+			s.deliver(ctx, claimed)
+		}
+		processed++
+	}
+	return processed
+}
+
+// claim decides what to do with a due send while its enrollment is locked,
+// and reports whether its step should be delivered. A claimed send is saved
+// as sending and its enrollment pushed back by the retry delay before it is
+// delivered, so that no other replica picks it up in the meantime.
+func (s *Scheduler) claim(due *models.DueSend) bool {
+	now := s.now()
+	enrollment := &due.Enrollment
+	step := due.Step
+
+	// The step was removed; the steps after it moved up into its position
+	if step == nil {
+		advance(due, enrollment.CurrentStep, now)
+		return false
+	}
+
+	// An earlier delivery never recorded how it went, e.g. the replica
+	// stopped halfway. It may have gone out, so it isn't sent again.
+	if due.Send.Status == models.SendStatusSending {
+		s.logger.Warn("delivery outcome unknown, not sending again",
+			zap.Int64("enrollment_id", enrollment.EnrollmentID),
+			zap.Int64("step_id", step.StepID),
+		)
+		due.Send.Status = models.SendStatusFailed
+		due.Send.Error = "delivery outcome unknown"
+		advance(due, step.StepOrder+1, now)
+		return false
+	}
+
+	// The step was sent already, or its window closed while waiting
+	if due.Send.Status == models.SendStatusSent || (step.EligibleEndTime > 0 && now.Unix() > step.EligibleEndTime) {
+		advance(due, step.StepOrder+1, now)
+		return false
+	}
+
+	// Every mailbox is unhealthy or at its daily limit
+	if due.MailboxUnavailable {
+		enrollment.NextSendAt = now.Add(time.Duration(s.conf.RetryDelaySeconds) * time.Second).Unix()
+		return false
+	}
+
+	due.Send.Status = models.SendStatusSending
+	due.Send.Attempts++
+	enrollment.NextSendAt = now.Add(time.Duration(s.conf.RetryDelaySeconds) * time.Second).Unix()
+	return true
+}
+
+// deliver sends a claimed step and records how it went.
+func (s *Scheduler) deliver(ctx context.Context, due *models.DueSend) {
+	enrollment := &due.Enrollment
+	step := due.Step
+
+	err := s.delivery.Deliver(ctx, due)
+	now := s.now()
+	if err != nil {
+		due.Send.Status = models.SendStatusFailed
+		due.Send.Error = err.Error()
+		if due.Send.Attempts < s.conf.MaxAttempts {
+			enrollment.NextSendAt = now.Add(time.Duration(s.conf.RetryDelaySeconds) * time.Second).Unix()
+		} else {
+			s.logger.Warn("giving up on send",
+				zap.Int64("enrollment_id", enrollment.EnrollmentID),
+				zap.Int64("step_id", step.StepID),
+				zap.Int("attempts", due.Send.Attempts),
+				zap.Error(err),
+			)
+			advance(due, step.StepOrder+1, now)
+		}
+	} else {
+		due.Send.Status = models.SendStatusSent
+		due.Send.Error = ""
+		due.Send.SentAt = now.Unix()
+		advance(due, step.StepOrder+1, now)
+	}
+
+	if err := s.sendRepo.RecordSend(ctx, due); err != nil {
+		s.logger.Error("failed to record send",
+			zap.Int64("enrollment_id", enrollment.EnrollmentID),
+			zap.Int64("step_id", step.StepID),
+			zap.Error(err),
+		)
+	}
+}
+
+// advance moves the enrollment to the first step at or after position that
+// can still be sent and wasn't sent already, finishing it when there is none.
+// Steps are followed in their current order, and a step is never sent twice
+// however the steps changed since the contact enrolled.
+func advance(due *models.DueSend, position int, now time.Time) {
+	enrollment := &due.Enrollment
+	for {
+		next, sendAt, ok := Next(due.Steps, position, now)
+		if !ok {
+			enrollment.Status = models.EnrollmentStatusFinished
+			enrollment.CurrentStep = 0
+			enrollment.CurrentStepID = 0
+			enrollment.NextSendAt = 0
+			return
+		}
+		if !due.SentStepIDs[next.StepID] {
+			enrollment.CurrentStep = next.StepOrder
+			enrollment.CurrentStepID = next.StepID
+			enrollment.NextSendAt = sendAt.Unix()
+			return
+		}
+		position = next.StepOrder + 1
+	}
+}
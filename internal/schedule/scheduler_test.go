@@ -0,0 +1,298 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"salesforge-api/internal/config"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"testing"
+	"time"
+)
+
+type fakeDelivery struct {
+	delivered []*models.DueSend
+	err       error
+}
+
+func (d *fakeDelivery) Deliver(ctx context.Context, due *models.DueSend) error {
+	d.delivered = append(d.delivered, due)
+	return d.err
+}
+
+var testSchedulerConfig = config.SchedulerConfig{
+	Enabled:           true,
+	IntervalSeconds:   60,
+	BatchSize:         10,
+	MaxAttempts:       2,
+	RetryDelaySeconds: 600,
+}
+
+func newTestScheduler(sendRepo persistence.SendRepository, delivery Delivery, now time.Time) *Scheduler {
+	s := NewScheduler(testSchedulerConfig, sendRepo, delivery, zap.NewNop())
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// processOnce makes the repository hand due to the scheduler once and then
+// report that nothing else is due. Claimed sends are recorded.
+func processOnce(t *testing.T, sendRepo *mocks.SendRepository, due *models.DueSend) {
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		process := args.Get(2).(persistence.ProcessFunc)
+		assert.NoError(t, process(args.Get(0).(context.Context), due))
+	}).Return(true, nil).Once()
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+	sendRepo.On("RecordSend", mock.Anything, due).Return(nil).Maybe()
+}
+
+func newTestDueSend() *models.DueSend {
+	steps := []models.Step{
+		{StepID: 1, StepOrder: 1, WaitDays: 0},
+		{StepID: 2, StepOrder: 2, WaitDays: 2},
+	}
+	return &models.DueSend{
+		Enrollment:  models.Enrollment{EnrollmentID: 1, Status: models.EnrollmentStatusActive, CurrentStep: 1, CurrentStepID: 1, NextSendAt: 1737000000},
+		Steps:       steps,
+		Step:        &steps[0],
+		Contact:     models.Contact{Email: "jane.doe@example.com"},
+		Send:        models.Send{EnrollmentID: 1, StepID: 1},
+		SentStepIDs: map[int64]bool{},
+	}
+}
+
+func TestTick_DeliversAndAdvances(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	processOnce(t, sendRepo, due)
+
+	processed := newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Equal(t, 1, processed)
+	assert.Len(t, delivery.delivered, 1)
+	assert.Equal(t, models.SendStatusSent, due.Send.Status)
+	assert.Equal(t, 1, due.Send.Attempts)
+	assert.Equal(t, 2, due.Enrollment.CurrentStep)
+	assert.Equal(t, int64(2), due.Enrollment.CurrentStepID)
+	assert.Equal(t, now.Add(48*time.Hour).Unix(), due.Enrollment.NextSendAt)
+	sendRepo.AssertExpectations(t)
+	sendRepo.AssertCalled(t, "RecordSend", mock.Anything, due)
+}
+
+func TestTick_ClaimsBeforeDelivering(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		process := args.Get(2).(persistence.ProcessFunc)
+		assert.NoError(t, process(args.Get(0).(context.Context), due))
+		// The claim is saved before anything is delivered.
+		assert.Empty(t, delivery.delivered)
+		assert.Equal(t, models.SendStatusSending, due.Send.Status)
+		assert.Equal(t, 1, due.Send.Attempts)
+		assert.Equal(t, now.Add(10*time.Minute).Unix(), due.Enrollment.NextSendAt)
+	}).Return(true, nil).Once()
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+	sendRepo.On("RecordSend", mock.Anything, due).Return(nil).Once()
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Len(t, delivery.delivered, 1)
+	assert.Equal(t, models.SendStatusSent, due.Send.Status)
+	sendRepo.AssertExpectations(t)
+}
+
+func TestTick_DoesNotResendUnknownOutcome(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	due.Send.Status = models.SendStatusSending
+	due.Send.Attempts = 1
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Empty(t, delivery.delivered)
+	assert.Equal(t, models.SendStatusFailed, due.Send.Status)
+	assert.Equal(t, 1, due.Send.Attempts)
+	assert.Equal(t, int64(2), due.Enrollment.CurrentStepID)
+	sendRepo.AssertNotCalled(t, "RecordSend", mock.Anything, mock.Anything)
+}
+
+func TestTick_FinishesAfterLastStep(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	due := newTestDueSend()
+	due.Enrollment.CurrentStep = 2
+	due.Enrollment.CurrentStepID = 2
+	due.Step = &due.Steps[1]
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, &fakeDelivery{}, now).Tick(context.Background())
+	assert.Equal(t, models.EnrollmentStatusFinished, due.Enrollment.Status)
+	assert.Equal(t, 0, due.Enrollment.CurrentStep)
+	assert.Equal(t, int64(0), due.Enrollment.CurrentStepID)
+	assert.Equal(t, int64(0), due.Enrollment.NextSendAt)
+}
+
+func TestTick_SkipsSentStep(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	due.Send.Status = models.SendStatusSent
+	due.Send.Attempts = 1
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Empty(t, delivery.delivered)
+	assert.Equal(t, 1, due.Send.Attempts)
+	assert.Equal(t, int64(2), due.Enrollment.CurrentStepID)
+}
+
+func TestTick_FollowsReorderedSteps(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	// Step 3 was sent first, then moved after the current step 1.
+	steps := []models.Step{
+		{StepID: 1, StepOrder: 1},
+		{StepID: 3, StepOrder: 2},
+		{StepID: 2, StepOrder: 3, WaitDays: 1},
+	}
+	due := newTestDueSend()
+	due.Steps = steps
+	due.Step = &steps[0]
+	due.SentStepIDs = map[int64]bool{3: true}
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Len(t, delivery.delivered, 1)
+	assert.Equal(t, 3, due.Enrollment.CurrentStep)
+	assert.Equal(t, int64(2), due.Enrollment.CurrentStepID)
+	assert.Equal(t, now.Add(24*time.Hour).Unix(), due.Enrollment.NextSendAt)
+}
+
+func TestTick_ContinuesAfterRemovedStep(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	// Step 1 was removed and step 2 moved up into its position.
+	steps := []models.Step{{StepID: 2, StepOrder: 1, WaitDays: 2}}
+	due := newTestDueSend()
+	due.Steps = steps
+	due.Step = nil
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Empty(t, delivery.delivered)
+	assert.Equal(t, 1, due.Enrollment.CurrentStep)
+	assert.Equal(t, int64(2), due.Enrollment.CurrentStepID)
+	assert.Equal(t, now.Add(48*time.Hour).Unix(), due.Enrollment.NextSendAt)
+}
+
+func TestTick_RetriesFailedDelivery(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	due := newTestDueSend()
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, &fakeDelivery{err: errors.New("connection refused")}, now).Tick(context.Background())
+	assert.Equal(t, models.SendStatusFailed, due.Send.Status)
+	assert.Equal(t, "connection refused", due.Send.Error)
+	assert.Equal(t, 1, due.Enrollment.CurrentStep)
+	assert.Equal(t, now.Add(10*time.Minute).Unix(), due.Enrollment.NextSendAt)
+}
+
+func TestTick_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	due := newTestDueSend()
+	due.Send.Attempts = 1
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, &fakeDelivery{err: errors.New("connection refused")}, now).Tick(context.Background())
+	assert.Equal(t, models.SendStatusFailed, due.Send.Status)
+	assert.Equal(t, 2, due.Send.Attempts)
+	assert.Equal(t, 2, due.Enrollment.CurrentStep)
+}
+
+func TestTick_SkipsClosedWindow(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	due.Steps[0].EligibleEndTime = 1736000000
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Empty(t, delivery.delivered)
+	assert.Equal(t, 0, due.Send.Attempts)
+	assert.Equal(t, 2, due.Enrollment.CurrentStep)
+}
+
+func TestTick_StopsOnRepositoryError(t *testing.T) {
+	sendRepo := new(mocks.SendRepository)
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("connection refused")).Once()
+
+	processed := newTestScheduler(sendRepo, &fakeDelivery{}, time.Now()).Tick(context.Background())
+	assert.Equal(t, 0, processed)
+	sendRepo.AssertExpectations(t)
+}
+
+func TestTick_PostponesFailingEnrollment(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	failed := &persistence.EnrollmentError{EnrollmentID: 7, Err: persistence.ErrNotFound}
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Return(true, failed).Once()
+	sendRepo.On("PostponeEnrollment", mock.Anything, int64(7), now.Add(10*time.Minute).Unix()).Return(nil).Once()
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	processOnce(t, sendRepo, due)
+
+	processed := newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Equal(t, 2, processed)
+	assert.Len(t, delivery.delivered, 1)
+	sendRepo.AssertExpectations(t)
+}
+
+func TestShutdown(t *testing.T) {
+	sendRepo := new(mocks.SendRepository)
+	sendRepo.On("ProcessDueSend", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	s := newTestScheduler(sendRepo, &fakeDelivery{}, time.Now())
+	s.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Shutdown(ctx))
+	assert.NoError(t, s.Shutdown(ctx))
+}
+
+func TestShutdown_NotStarted(t *testing.T) {
+	s := newTestScheduler(new(mocks.SendRepository), &fakeDelivery{}, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Shutdown(ctx))
+	assert.NoError(t, ctx.Err())
+}
+
+func TestTick_PostponesWithoutMailbox(t *testing.T) {
+	now := time.Unix(1737000000, 0)
+	sendRepo := new(mocks.SendRepository)
+	delivery := &fakeDelivery{}
+	due := newTestDueSend()
+	due.MailboxUnavailable = true
+	processOnce(t, sendRepo, due)
+
+	newTestScheduler(sendRepo, delivery, now).Tick(context.Background())
+	assert.Empty(t, delivery.delivered)
+	assert.Equal(t, 0, due.Send.Attempts)
+	assert.Equal(t, 1, due.Enrollment.CurrentStep)
+	assert.Equal(t, now.Add(10*time.Minute).Unix(), due.Enrollment.NextSendAt)
+}
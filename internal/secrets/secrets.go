@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"salesforge-api/internal/config"
+	"strings"
+)
+
+// KeySize is the size of the AES-256 keys secrets are encrypted with.
+const KeySize = 32
+
+// sealedPrefix starts every encrypted value, so that the format can change.
+const sealedPrefix = "v1:"
+
+var (
+	ErrUndecryptable = errors.New("secret can't be decrypted")
+	ErrNoKey         = errors.New("no secrets key is configured")
+)
+
+// Cipher encrypts secrets stored in the database, such as the SMTP passwords
+// of mailboxes, with AES-256-GCM. The first key encrypts; the others are
+// only tried when decrypting, so that a new key can be added before the old
+// one is dropped.
+type Cipher struct {
+	aeads []cipher.AEAD
+}
+
+// NewCipher reads the base64 encoded keys from the KeyEnv variable and
+// KeyFile, one per line, in that order. Without either it returns a Cipher
+// that fails with ErrNoKey, so that a deployment without mailboxes needs no
+// key.
+func NewCipher(conf config.SecretsConfig) (*Cipher, error) {
+	if conf.KeyEnv == "" && conf.KeyFile == "" {
+		return &Cipher{}, nil
+	}
+	var encoded []string
+	if conf.KeyEnv != "" {
+		key := os.Getenv(conf.KeyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("secrets key env %s is not set", conf.KeyEnv)
+		}
+		encoded = append(encoded, key)
+	}
+	if conf.KeyFile != "" {
+		b, err := os.ReadFile(conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets key file: %w", err)
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if key := strings.TrimSpace(line); key != "" {
+				encoded = append(encoded, key)
+			}
+		}
+	}
+
+	var keys [][]byte
+	for _, e := range encoded {
+		key, err := base64.StdEncoding.DecodeString(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secrets key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return NewCipherFromKeys(keys...)
+}
+
+// NewCipherFromKeys returns a Cipher that encrypts with the first key.
+func NewCipherFromKeys(keys ...[]byte) (*Cipher, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("a secrets key is required")
+	}
+	c := &Cipher{}
+	for _, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("secrets keys must be %d bytes, got %d", KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.aeads = append(c.aeads, aead)
+	}
+	return c, nil
+}
+
+// Encrypt seals plaintext with a random nonce. Empty secrets stay empty, even
+// without a key.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	if len(c.aeads) == 0 {
+		return "", ErrNoKey
+	}
+	aead := c.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value made by Encrypt, failing with ErrUndecryptable when
+// none of the keys can.
+func (c *Cipher) Decrypt(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	if len(c.aeads) == 0 {
+		return "", ErrNoKey
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", fmt.Errorf("%w: not encrypted", ErrUndecryptable)
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUndecryptable, err)
+	}
+	for _, aead := range c.aeads {
+		if len(b) < aead.NonceSize() {
+			break
+		}
+		plaintext, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+		if err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", fmt.Errorf("%w: no key matches", ErrUndecryptable)
+}
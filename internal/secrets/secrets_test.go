@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"salesforge-api/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	c, err := NewCipherFromKeys(bytes.Repeat([]byte{1}, KeySize))
+	assert.NoError(t, err)
+
+	sealed, err := c.Encrypt("hunter2")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, sealedPrefix))
+	assert.NotContains(t, sealed, "hunter2")
+
+	again, err := c.Encrypt("hunter2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	plaintext, err := c.Decrypt(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	empty, err := c.Encrypt("")
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestDecrypt_Rejects(t *testing.T) {
+	c, err := NewCipherFromKeys(bytes.Repeat([]byte{1}, KeySize))
+	assert.NoError(t, err)
+	other, err := NewCipherFromKeys(bytes.Repeat([]byte{2}, KeySize))
+	assert.NoError(t, err)
+	sealed, err := other.Encrypt("hunter2")
+	assert.NoError(t, err)
+
+	for name, value := range map[string]string{
+		"plaintext":    "hunter2",
+		"bad encoding": sealedPrefix + "!!",
+		"too short":    sealedPrefix + "AAAA",
+		"other key":    sealed,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.Decrypt(value)
+			assert.ErrorIs(t, err, ErrUndecryptable)
+		})
+	}
+}
+
+func TestNewCipher_RotatesKeys(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, KeySize)
+	newKey := bytes.Repeat([]byte{2}, KeySize)
+	old, err := NewCipherFromKeys(oldKey)
+	assert.NoError(t, err)
+	sealed, err := old.Encrypt("hunter2")
+	assert.NoError(t, err)
+
+	t.Setenv("TEST_SECRETS_KEY", base64.StdEncoding.EncodeToString(newKey))
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	assert.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(oldKey)+"\n"), 0o600))
+
+	c, err := NewCipher(config.SecretsConfig{KeyEnv: "TEST_SECRETS_KEY", KeyFile: keyFile})
+	assert.NoError(t, err)
+	plaintext, err := c.Decrypt(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// New secrets are encrypted with the first key only.
+	resealed, err := c.Encrypt("hunter2")
+	assert.NoError(t, err)
+	_, err = old.Decrypt(resealed)
+	assert.ErrorIs(t, err, ErrUndecryptable)
+}
+
+func TestNewCipher_Rejects(t *testing.T) {
+	t.Setenv("TEST_SECRETS_KEY", base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err := NewCipher(config.SecretsConfig{KeyEnv: "TEST_SECRETS_KEY"})
+	assert.Error(t, err)
+
+	_, err = NewCipher(config.SecretsConfig{KeyEnv: "TEST_SECRETS_KEY_UNSET"})
+	assert.Error(t, err)
+
+	_, err = NewCipherFromKeys()
+	assert.Error(t, err)
+}
+
+func TestNewCipher_WithoutKey(t *testing.T) {
+	c, err := NewCipher(config.SecretsConfig{})
+	assert.NoError(t, err)
+
+	empty, err := c.Encrypt("")
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = c.Encrypt("hunter2")
+	assert.ErrorIs(t, err, ErrNoKey)
+	_, err = c.Decrypt(sealedPrefix + "AAAA")
+	assert.ErrorIs(t, err, ErrNoKey)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so that leaked keys are easy to spot.
+// A key is the prefix, a random id and a random secret, joined by
+// underscores; everything before the secret identifies the key.
+const APIKeyPrefix = "sf"
+
+type apiKeyService struct {
+	apiKeyRepo persistence.APIKeyRepository
+	now        func() time.Time
+}
+
+type APIKeyService interface {
+	AddAPIKey(ctx context.Context, add *models.AddAPIKeyRequest) (apiKey *models.APIKey, key string, err error)
+	ListAPIKeys(ctx context.Context, list *models.ListAPIKeysRequest) (apiKeys []models.APIKey, err error)
+	RevokeAPIKey(ctx context.Context, revoke *models.RevokeAPIKeyRequest) (apiKeyId int64, err error)
+	AuthenticateAPIKey(ctx context.Context, key string) (identity *auth.Identity, err error)
+}
+
+func NewAPIKeyService(
+	apiKeyRepo persistence.APIKeyRepository,
+) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		now:        time.Now,
+	}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIKey generates a key and the prefix it is looked up by.
+func newAPIKey() (key string, prefix string, err error) {
+	id, err := randomHex(6)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyPrefix + "_" + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+func apiKeyPrefix(key string) (string, bool) {
+	i := strings.LastIndex(key, "_")
+	if i <= 0 || !strings.HasPrefix(key, APIKeyPrefix+"_") {
+		return "", false
+	}
+	return key[:i], true
+}
+
+// checkKeyManagement only lets owners of the account manage its API keys,
+// so API keys can never be used to mint more.
+func checkKeyManagement(ctx context.Context, accountId int64) error {
+	if err := auth.CheckAccount(ctx, accountId); err != nil {
+		return err
+	}
+	return auth.CheckPermission(ctx, auth.PermissionManageAPIKeys)
+}
+
+// AddAPIKey creates a key and returns it; only its hash is stored, so it
+// can't be shown again.
+func (s *apiKeyService) AddAPIKey(ctx context.Context, add *models.AddAPIKeyRequest) (apiKey *models.APIKey, key string, err error) {
+	if err := checkKeyManagement(ctx, add.AccountID); err != nil {
+		return nil, "", newAppError("failed to add api key", err)
+	}
+
+	key, prefix, err := newAPIKey()
+	if err != nil {
+		return nil, "", newAppError("failed to generate api key", err)
+	}
+	apiKey = &models.APIKey{
+		AccountID: add.AccountID,
+		CreatedAt: s.now().Unix(),
+		Name:      add.Name,
+		Prefix:    prefix,
+		Scopes:    add.Scopes,
+		KeyHash:   hashAPIKey(key),
+	}
+
+	apiKey.APIKeyID, err = s.apiKeyRepo.AddAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, "", newAppError("failed to add api key", err)
+	}
+	apiKey.KeyHash = ""
+	return apiKey, key, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, list *models.ListAPIKeysRequest) (apiKeys []models.APIKey, err error) {
+	if err := checkKeyManagement(ctx, list.AccountID); err != nil {
+		return nil, newAppError("failed to list api keys", err)
+	}
+
+	apiKeys, err = s.apiKeyRepo.ListAPIKeys(ctx, list)
+	if err != nil {
+		return nil, newAppError("failed to list api keys", err)
+	}
+	return apiKeys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, revoke *models.RevokeAPIKeyRequest) (apiKeyId int64, err error) {
+	if err := checkKeyManagement(ctx, revoke.AccountID); err != nil {
+		return 0, newAppError("failed to revoke api key", err)
+	}
+
+	apiKeyId, err = s.apiKeyRepo.RevokeAPIKey(ctx, revoke)
+	if err != nil {
+		return 0, newAppError("failed to revoke api key", err)
+	}
+	return apiKeyId, nil
+}
+
+// AuthenticateAPIKey returns the identity a key acts as, failing with
+// auth.ErrInvalidAPIKey or auth.ErrRevokedAPIKey. It records when the key
+// was last used.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (identity *auth.Identity, err error) {
+	prefix, ok := apiKeyPrefix(key)
+	if !ok {
+		return nil, newAppError("failed to authenticate api key", auth.ErrInvalidAPIKey)
+	}
+
+	apiKey, err := s.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, persistence.ErrNotFound) {
+		return nil, newAppError("failed to authenticate api key", auth.ErrInvalidAPIKey)
+	}
+	if err != nil {
+		return nil, newAppError("failed to get api key", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, newAppError("failed to authenticate api key", auth.ErrInvalidAPIKey)
+	}
+	if apiKey.Revoked() {
+		return nil, newAppError("failed to authenticate api key", auth.ErrRevokedAPIKey)
+	}
+
+	err = s.apiKeyRepo.TouchAPIKey(ctx, apiKey.APIKeyID, s.now())
+	if err != nil {
+		return nil, newAppError("failed to update api key", err)
+	}
+
+	return &auth.Identity{
+		AccountID: apiKey.AccountID,
+		Roles:     []string{auth.RoleAPI},
+		APIKeyID:  apiKey.APIKeyID,
+		Scopes:    apiKey.Scopes,
+	}, nil
+}
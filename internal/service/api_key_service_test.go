@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddAPIKey_Success(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	svc := NewAPIKeyService(mockRepo)
+
+	add := models.AddAPIKeyRequest{AccountID: 1, Name: "CRM sync", Scopes: []string{models.APIKeyScopeWrite}}
+
+	var stored *models.APIKey
+	mockRepo.On("AddAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		copied := *args.Get(1).(*models.APIKey)
+		stored = &copied
+	}).Return(int64(7), nil)
+
+	ctx := context.Background()
+	apiKey, key, err := svc.AddAPIKey(ctx, &add)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), apiKey.APIKeyID)
+	assert.True(t, strings.HasPrefix(key, apiKey.Prefix+"_"))
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix+"_"))
+	assert.Empty(t, apiKey.KeyHash)
+
+	// Only the hash of the key is stored.
+	assert.Equal(t, hashAPIKey(key), stored.KeyHash)
+	assert.NotEqual(t, key, stored.KeyHash)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddAPIKey_WithAPIKey(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	svc := NewAPIKeyService(mockRepo)
+
+	add := models.AddAPIKeyRequest{AccountID: 1, Name: "CRM sync", Scopes: []string{models.APIKeyScopeWrite}}
+
+	ctx := auth.NewContext(context.Background(), &auth.Identity{AccountID: 1, Roles: []string{auth.RoleAPI}, APIKeyID: 3, Scopes: []string{models.APIKeyScopeWrite}})
+	_, _, err := svc.AddAPIKey(ctx, &add)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusForbidden, appErr.Code)
+	mockRepo.AssertNotCalled(t, "AddAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	key := "sf_0123456789ab_" + strings.Repeat("f", 64)
+	stored := &models.APIKey{
+		AccountID: 1,
+		APIKeyID:  7,
+		Prefix:    "sf_0123456789ab",
+		Scopes:    []string{models.APIKeyScopeRead},
+		KeyHash:   hashAPIKey(key),
+	}
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name       string
+		key        string
+		revokedAt  int64
+		lookup     bool
+		lookupErr  error
+		wantErr    error
+		wantCode   int
+		wantTouch  bool
+		wantScopes []string
+	}{
+		{name: "valid", key: key, lookup: true, wantTouch: true, wantScopes: []string{models.APIKeyScopeRead}},
+		{name: "wrong secret", key: "sf_0123456789ab_" + strings.Repeat("e", 64), lookup: true, wantErr: auth.ErrInvalidAPIKey, wantCode: http.StatusUnauthorized},
+		{name: "revoked", key: key, revokedAt: now.Unix(), lookup: true, wantErr: auth.ErrRevokedAPIKey, wantCode: http.StatusUnauthorized},
+		{name: "unknown prefix", key: key, lookup: true, lookupErr: persistence.ErrNotFound, wantErr: auth.ErrInvalidAPIKey, wantCode: http.StatusUnauthorized},
+		{name: "not a key", key: "Bearer abc", wantErr: auth.ErrInvalidAPIKey, wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.APIKeyRepository)
+			svc := &apiKeyService{apiKeyRepo: mockRepo, now: func() time.Time { return now }}
+
+			if tt.lookup {
+				apiKey := *stored
+				apiKey.RevokedAt = tt.revokedAt
+				if tt.lookupErr != nil {
+					mockRepo.On("GetAPIKeyByPrefix", mock.Anything, "sf_0123456789ab").Return(nil, tt.lookupErr)
+				} else {
+					mockRepo.On("GetAPIKeyByPrefix", mock.Anything, "sf_0123456789ab").Return(&apiKey, nil)
+				}
+			}
+			if tt.wantTouch {
+				mockRepo.On("TouchAPIKey", mock.Anything, int64(7), now).Return(nil)
+			}
+
+			identity, err := svc.AuthenticateAPIKey(context.Background(), tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				var appErr *sfErr.AppError
+				assert.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				mockRepo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &auth.Identity{AccountID: 1, Roles: []string{auth.RoleAPI}, APIKeyID: 7, Scopes: tt.wantScopes}, identity)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+)
+
+type contactService struct {
+	contactRepo persistence.ContactRepository
+}
+
+type ContactService interface {
+	AddContact(ctx context.Context, contact *models.Contact) (contactId int64, err error)
+	GetContact(ctx context.Context, get *models.GetContactRequest) (contact *models.Contact, err error)
+	ListContacts(ctx context.Context, list *models.ListContactsRequest) (contacts []models.Contact, next *models.ContactCursor, err error)
+	UpdateContact(ctx context.Context, update *models.UpdateContactRequest) (contactId int64, err error)
+	DeleteContact(ctx context.Context, delete *models.DeleteContactRequest) (contactId int64, err error)
+}
+
+func NewContactService(
+	contactRepo persistence.ContactRepository,
+) ContactService {
+	return &contactService{
+		contactRepo: contactRepo,
+	}
+}
+
+func (s *contactService) AddContact(ctx context.Context, contact *models.Contact) (contactId int64, err error) {
+	if err := auth.CheckAccount(ctx, contact.AccountID); err != nil {
+		return 0, newAppError("failed to add contact", err)
+	}
+
+	contactId, err = s.contactRepo.AddContact(ctx, contact)
+	if err != nil {
+		return 0, newAppError("failed to add contact", err)
+	}
+	return contactId, nil
+}
+
+func (s *contactService) GetContact(ctx context.Context, get *models.GetContactRequest) (contact *models.Contact, err error) {
+	if err := auth.CheckAccount(ctx, get.AccountID); err != nil {
+		return nil, newAppError("failed to get contact", err)
+	}
+
+	contact, err = s.contactRepo.GetContact(ctx, get)
+	if err != nil {
+		return nil, newAppError("failed to get contact", err)
+	}
+	return contact, nil
+}
+
+func (s *contactService) ListContacts(ctx context.Context, list *models.ListContactsRequest) (contacts []models.Contact, next *models.ContactCursor, err error) {
+	if err := auth.CheckAccount(ctx, list.AccountID); err != nil {
+		return nil, nil, newAppError("failed to list contacts", err)
+	}
+
+	contacts, next, err = s.contactRepo.ListContacts(ctx, list)
+	if err != nil {
+		return nil, nil, newAppError("failed to list contacts", err)
+	}
+	return contacts, next, nil
+}
+
+func (s *contactService) UpdateContact(ctx context.Context, update *models.UpdateContactRequest) (contactId int64, err error) {
+	if err := auth.CheckAccount(ctx, update.AccountID); err != nil {
+		return 0, newAppError("failed to update contact", err)
+	}
+
+	contactId, err = s.contactRepo.UpdateContact(ctx, update)
+	if err != nil {
+		return 0, newAppError("failed to update contact", err)
+	}
+	return contactId, nil
+}
+
+func (s *contactService) DeleteContact(ctx context.Context, delete *models.DeleteContactRequest) (contactId int64, err error) {
+	if err := auth.CheckAccount(ctx, delete.AccountID); err != nil {
+		return 0, newAppError("failed to delete contact", err)
+	}
+
+	contactId, err = s.contactRepo.DeleteContact(ctx, delete)
+	if err != nil {
+		return 0, newAppError("failed to delete contact", err)
+	}
+	return contactId, nil
+}
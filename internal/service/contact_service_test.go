@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"testing"
+)
+
+func TestAddContact_Success(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	contact := models.Contact{
+		AccountID: 1,
+		Email:     "jane.doe@example.com",
+		FirstName: "Jane",
+		Timezone:  "UTC",
+	}
+
+	mockRepo.On("AddContact", mock.Anything, &contact).Return(int64(1), nil)
+
+	ctx := context.Background()
+	contactId, err := svc.AddContact(ctx, &contact)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), contactId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddContact_Duplicate(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	contact := models.Contact{
+		AccountID: 1,
+		Email:     "jane.doe@example.com",
+		Timezone:  "UTC",
+	}
+
+	mockRepo.On("AddContact", mock.Anything, &contact).Return(int64(0), persistence.ErrConflict)
+
+	ctx := context.Background()
+	contactId, err := svc.AddContact(ctx, &contact)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusConflict, appErr.Code)
+	assert.Equal(t, int64(0), contactId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetContact_Success(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	get := models.GetContactRequest{
+		AccountID: 1,
+		ContactID: 1,
+	}
+	contact := models.Contact{
+		AccountID: 1,
+		ContactID: 1,
+		Email:     "jane.doe@example.com",
+	}
+
+	mockRepo.On("GetContact", mock.Anything, &get).Return(&contact, nil)
+
+	ctx := context.Background()
+	gotContact, err := svc.GetContact(ctx, &get)
+	assert.NoError(t, err)
+	assert.Equal(t, &contact, gotContact)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetContact_NotFound(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	get := models.GetContactRequest{
+		AccountID: 1,
+		ContactID: 1,
+	}
+
+	mockRepo.On("GetContact", mock.Anything, &get).Return(nil, persistence.ErrNotFound)
+
+	ctx := context.Background()
+	contact, err := svc.GetContact(ctx, &get)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	assert.Nil(t, contact)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListContacts_Success(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	list := models.ListContactsRequest{
+		AccountID: 1,
+		Limit:     1,
+	}
+	contacts := []models.Contact{
+		{AccountID: 1, ContactID: 1, Email: "jane.doe@example.com"},
+	}
+	next := &models.ContactCursor{ContactID: 1}
+
+	mockRepo.On("ListContacts", mock.Anything, &list).Return(contacts, next, nil)
+
+	ctx := context.Background()
+	gotContacts, gotNext, err := svc.ListContacts(ctx, &list)
+	assert.NoError(t, err)
+	assert.Equal(t, contacts, gotContacts)
+	assert.Equal(t, next, gotNext)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateContact_Success(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	update := models.UpdateContactRequest{
+		AccountID: 1,
+		ContactID: 1,
+		Company:   &[]string{"Initech"}[0],
+	}
+
+	mockRepo.On("UpdateContact", mock.Anything, &update).Return(int64(1), nil)
+
+	ctx := context.Background()
+	contactId, err := svc.UpdateContact(ctx, &update)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), contactId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteContact_Failure(t *testing.T) {
+	mockRepo := new(mocks.ContactRepository)
+	svc := NewContactService(mockRepo)
+
+	delete := models.DeleteContactRequest{
+		AccountID: 1,
+		ContactID: 1,
+	}
+
+	mockRepo.On("DeleteContact", mock.Anything, &delete).Return(int64(0), errors.New("db error"))
+
+	ctx := context.Background()
+	contactId, err := svc.DeleteContact(ctx, &delete)
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), contactId)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/schedule"
+	"time"
+)
+
+type enrollmentService struct {
+	sequenceRepo   persistence.SequenceRepository
+	enrollmentRepo persistence.EnrollmentRepository
+}
+
+type EnrollmentService interface {
+	AddEnrollments(ctx context.Context, add *models.AddEnrollmentsRequest) (enrollments []models.Enrollment, skippedContactIds []int64, err error)
+	UpdateEnrollment(ctx context.Context, update *models.UpdateEnrollmentRequest) (enrollmentId int64, err error)
+	DeleteEnrollment(ctx context.Context, delete *models.DeleteEnrollmentRequest) (enrollmentId int64, err error)
+}
+
+func NewEnrollmentService(
+	sequenceRepo persistence.SequenceRepository,
+	enrollmentRepo persistence.EnrollmentRepository,
+) EnrollmentService {
+	return &enrollmentService{
+		sequenceRepo:   sequenceRepo,
+		enrollmentRepo: enrollmentRepo,
+	}
+}
+
+// AddEnrollments schedules the first step that can still be sent; contacts
+// enrolled into a sequence with nothing left to send are finished right away.
+func (s *enrollmentService) AddEnrollments(ctx context.Context, add *models.AddEnrollmentsRequest) (enrollments []models.Enrollment, skippedContactIds []int64, err error) {
+	if err := auth.CheckAccount(ctx, add.AccountID); err != nil {
+		return nil, nil, newAppError("failed to enroll contacts", err)
+	}
+
+	get := &models.GetSequenceRequest{
+		AccountID:  add.AccountID,
+		SequenceID: add.SequenceID,
+	}
+	_, steps, err := s.sequenceRepo.GetSequence(ctx, get)
+	if err != nil {
+		return nil, nil, newAppError("failed to enroll contacts", err)
+	}
+
+	enrollment := &models.Enrollment{
+		AccountID:  add.AccountID,
+		SequenceID: add.SequenceID,
+		Status:     models.EnrollmentStatusFinished,
+	}
+	if step, sendAt, ok := schedule.Next(steps, 1, time.Now()); ok {
+		enrollment.Status = models.EnrollmentStatusActive
+		enrollment.CurrentStep = step.StepOrder
+		enrollment.CurrentStepID = step.StepID
+		enrollment.NextSendAt = sendAt.Unix()
+	}
+
+	enrollments, skippedContactIds, err = s.enrollmentRepo.AddEnrollments(ctx, enrollment, add.ContactIDs)
+	if err != nil {
+		return nil, nil, newAppError("failed to enroll contacts", err)
+	}
+	return enrollments, skippedContactIds, nil
+}
+
+// UpdateEnrollment pauses or resumes an enrollment. A resumed enrollment
+// waits for its current step's WaitDays again, counted from now.
+func (s *enrollmentService) UpdateEnrollment(ctx context.Context, update *models.UpdateEnrollmentRequest) (enrollmentId int64, err error) {
+	if err := auth.CheckAccount(ctx, update.AccountID); err != nil {
+		return 0, newAppError("failed to update enrollment", err)
+	}
+
+	resume := func(enrollment *models.Enrollment, steps []models.Step) {
+		schedule.Resume(enrollment, steps, time.Now())
+	}
+	enrollmentId, err = s.enrollmentRepo.UpdateEnrollment(ctx, update, resume)
+	if err != nil {
+		return 0, newAppError("failed to update enrollment", err)
+	}
+	return enrollmentId, nil
+}
+
+func (s *enrollmentService) DeleteEnrollment(ctx context.Context, delete *models.DeleteEnrollmentRequest) (enrollmentId int64, err error) {
+	if err := auth.CheckAccount(ctx, delete.AccountID); err != nil {
+		return 0, newAppError("failed to delete enrollment", err)
+	}
+
+	enrollmentId, err = s.enrollmentRepo.DeleteEnrollment(ctx, delete)
+	if err != nil {
+		return 0, newAppError("failed to delete enrollment", err)
+	}
+	return enrollmentId, nil
+}
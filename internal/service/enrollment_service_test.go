@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"testing"
+)
+
+func TestAddEnrollments_Success(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockEnrollmentRepo := new(mocks.EnrollmentRepository)
+	svc := NewEnrollmentService(mockSequenceRepo, mockEnrollmentRepo)
+
+	add := models.AddEnrollmentsRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		ContactIDs: []int64{1, 2},
+	}
+	steps := []models.Step{
+		{StepID: 1, StepOrder: 1, WaitDays: 2},
+	}
+	enrollments := []models.Enrollment{
+		{EnrollmentID: 1, AccountID: 1, SequenceID: 1, ContactID: 1, Status: models.EnrollmentStatusActive, CurrentStep: 1},
+	}
+
+	mockSequenceRepo.On("GetSequence", mock.Anything, &models.GetSequenceRequest{AccountID: 1, SequenceID: 1}).Return(&models.Sequence{}, steps, nil)
+	mockEnrollmentRepo.On("AddEnrollments", mock.Anything, mock.MatchedBy(func(e *models.Enrollment) bool {
+		return e.Status == models.EnrollmentStatusActive && e.CurrentStep == 1 && e.CurrentStepID == 1 && e.NextSendAt > 0
+	}), add.ContactIDs).Return(enrollments, []int64{2}, nil)
+
+	ctx := context.Background()
+	gotEnrollments, skipped, err := svc.AddEnrollments(ctx, &add)
+	assert.NoError(t, err)
+	assert.Equal(t, enrollments, gotEnrollments)
+	assert.Equal(t, []int64{2}, skipped)
+	mockSequenceRepo.AssertExpectations(t)
+	mockEnrollmentRepo.AssertExpectations(t)
+}
+
+func TestAddEnrollments_NoSteps(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockEnrollmentRepo := new(mocks.EnrollmentRepository)
+	svc := NewEnrollmentService(mockSequenceRepo, mockEnrollmentRepo)
+
+	add := models.AddEnrollmentsRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		ContactIDs: []int64{1},
+	}
+
+	mockSequenceRepo.On("GetSequence", mock.Anything, mock.Anything).Return(&models.Sequence{}, []models.Step{}, nil)
+	mockEnrollmentRepo.On("AddEnrollments", mock.Anything, mock.MatchedBy(func(e *models.Enrollment) bool {
+		return e.Status == models.EnrollmentStatusFinished && e.NextSendAt == 0
+	}), add.ContactIDs).Return([]models.Enrollment{}, []int64{}, nil)
+
+	ctx := context.Background()
+	_, _, err := svc.AddEnrollments(ctx, &add)
+	assert.NoError(t, err)
+	mockEnrollmentRepo.AssertExpectations(t)
+}
+
+func TestAddEnrollments_SequenceNotFound(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockEnrollmentRepo := new(mocks.EnrollmentRepository)
+	svc := NewEnrollmentService(mockSequenceRepo, mockEnrollmentRepo)
+
+	add := models.AddEnrollmentsRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		ContactIDs: []int64{1},
+	}
+
+	mockSequenceRepo.On("GetSequence", mock.Anything, mock.Anything).Return(nil, nil, persistence.ErrNotFound)
+
+	ctx := context.Background()
+	_, _, err := svc.AddEnrollments(ctx, &add)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	mockEnrollmentRepo.AssertNotCalled(t, "AddEnrollments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateEnrollment_InvalidTransition(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockEnrollmentRepo := new(mocks.EnrollmentRepository)
+	svc := NewEnrollmentService(mockSequenceRepo, mockEnrollmentRepo)
+
+	update := models.UpdateEnrollmentRequest{
+		AccountID:    1,
+		EnrollmentID: 1,
+		Status:       models.EnrollmentStatusPaused,
+	}
+
+	mockEnrollmentRepo.On("UpdateEnrollment", mock.Anything, &update, mock.Anything).Return(int64(0), persistence.ErrConflict)
+
+	ctx := context.Background()
+	enrollmentId, err := svc.UpdateEnrollment(ctx, &update)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusConflict, appErr.Code)
+	assert.Equal(t, int64(0), enrollmentId)
+	mockEnrollmentRepo.AssertExpectations(t)
+}
+
+func TestDeleteEnrollment_Success(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockEnrollmentRepo := new(mocks.EnrollmentRepository)
+	svc := NewEnrollmentService(mockSequenceRepo, mockEnrollmentRepo)
+
+	delete := models.DeleteEnrollmentRequest{
+		AccountID:    1,
+		EnrollmentID: 1,
+	}
+
+	mockEnrollmentRepo.On("DeleteEnrollment", mock.Anything, &delete).Return(int64(1), nil)
+
+	ctx := context.Background()
+	enrollmentId, err := svc.DeleteEnrollment(ctx, &delete)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), enrollmentId)
+	mockEnrollmentRepo.AssertExpectations(t)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/persistence"
+)
+
+// newAppError wraps a repository error into an AppError whose code reflects
+// the domain error it carries; anything unrecognized is a 500.
+func newAppError(message string, err error) *errors.AppError {
+	code := http.StatusInternalServerError
+	switch {
+	case stdErrors.Is(err, persistence.ErrNotFound):
+		code = http.StatusNotFound
+	case stdErrors.Is(err, persistence.ErrConflict):
+		code = http.StatusConflict
+	case stdErrors.Is(err, persistence.ErrValidation):
+		code = http.StatusBadRequest
+	case stdErrors.Is(err, auth.ErrForbidden):
+		code = http.StatusForbidden
+	case stdErrors.Is(err, auth.ErrUnauthenticated):
+		code = http.StatusUnauthorized
+	}
+	return errors.NewAppError(code, message, err)
+}
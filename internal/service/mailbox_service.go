@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/secrets"
+)
+
+type mailboxService struct {
+	mailboxRepo persistence.MailboxRepository
+}
+
+type MailboxService interface {
+	AddMailbox(ctx context.Context, mailbox *models.Mailbox) (mailboxId int64, err error)
+	GetMailbox(ctx context.Context, get *models.GetMailboxRequest) (mailbox *models.Mailbox, err error)
+	ListMailboxes(ctx context.Context, list *models.ListMailboxesRequest) (mailboxes []models.Mailbox, err error)
+	UpdateMailbox(ctx context.Context, update *models.UpdateMailboxRequest) (mailboxId int64, err error)
+	DeleteMailbox(ctx context.Context, delete *models.DeleteMailboxRequest) (mailboxId int64, err error)
+	GetSequenceMailboxes(ctx context.Context, get *models.GetSequenceMailboxesRequest) (mailboxIds []int64, err error)
+	SetSequenceMailboxes(ctx context.Context, set *models.SetSequenceMailboxesRequest) (sequenceId int64, err error)
+}
+
+func NewMailboxService(
+	mailboxRepo persistence.MailboxRepository,
+) MailboxService {
+	return &mailboxService{
+		mailboxRepo: mailboxRepo,
+	}
+}
+
+func (s *mailboxService) AddMailbox(ctx context.Context, mailbox *models.Mailbox) (mailboxId int64, err error) {
+	if err := auth.CheckAccount(ctx, mailbox.AccountID); err != nil {
+		return 0, newAppError("failed to add mailbox", err)
+	}
+
+	mailboxId, err = s.mailboxRepo.AddMailbox(ctx, mailbox)
+	if err != nil {
+		return 0, newMailboxWriteError("failed to add mailbox", err)
+	}
+	return mailboxId, nil
+}
+
+func (s *mailboxService) GetMailbox(ctx context.Context, get *models.GetMailboxRequest) (mailbox *models.Mailbox, err error) {
+	if err := auth.CheckAccount(ctx, get.AccountID); err != nil {
+		return nil, newAppError("failed to get mailbox", err)
+	}
+
+	mailbox, err = s.mailboxRepo.GetMailbox(ctx, get)
+	if err != nil {
+		return nil, newAppError("failed to get mailbox", err)
+	}
+	return mailbox, nil
+}
+
+func (s *mailboxService) ListMailboxes(ctx context.Context, list *models.ListMailboxesRequest) (mailboxes []models.Mailbox, err error) {
+	if err := auth.CheckAccount(ctx, list.AccountID); err != nil {
+		return nil, newAppError("failed to list mailboxes", err)
+	}
+
+	mailboxes, err = s.mailboxRepo.ListMailboxes(ctx, list)
+	if err != nil {
+		return nil, newAppError("failed to list mailboxes", err)
+	}
+	return mailboxes, nil
+}
+
+func (s *mailboxService) UpdateMailbox(ctx context.Context, update *models.UpdateMailboxRequest) (mailboxId int64, err error) {
+	if err := auth.CheckAccount(ctx, update.AccountID); err != nil {
+		return 0, newAppError("failed to update mailbox", err)
+	}
+
+	mailboxId, err = s.mailboxRepo.UpdateMailbox(ctx, update)
+	if err != nil {
+		return 0, newMailboxWriteError("failed to update mailbox", err)
+	}
+	return mailboxId, nil
+}
+
+func (s *mailboxService) DeleteMailbox(ctx context.Context, delete *models.DeleteMailboxRequest) (mailboxId int64, err error) {
+	if err := auth.CheckAccount(ctx, delete.AccountID); err != nil {
+		return 0, newAppError("failed to delete mailbox", err)
+	}
+
+	mailboxId, err = s.mailboxRepo.DeleteMailbox(ctx, delete)
+	if err != nil {
+		return 0, newAppError("failed to delete mailbox", err)
+	}
+	return mailboxId, nil
+}
+
+func (s *mailboxService) GetSequenceMailboxes(ctx context.Context, get *models.GetSequenceMailboxesRequest) (mailboxIds []int64, err error) {
+	if err := auth.CheckAccount(ctx, get.AccountID); err != nil {
+		return nil, newAppError("failed to get sequence mailboxes", err)
+	}
+
+	mailboxIds, err = s.mailboxRepo.GetSequenceMailboxes(ctx, get)
+	if err != nil {
+		return nil, newAppError("failed to get sequence mailboxes", err)
+	}
+	return mailboxIds, nil
+}
+
+func (s *mailboxService) SetSequenceMailboxes(ctx context.Context, set *models.SetSequenceMailboxesRequest) (sequenceId int64, err error) {
+	if err := auth.CheckAccount(ctx, set.AccountID); err != nil {
+		return 0, newAppError("failed to set sequence mailboxes", err)
+	}
+
+	sequenceId, err = s.mailboxRepo.SetSequenceMailboxes(ctx, set)
+	if err != nil {
+		return 0, newAppError("failed to set sequence mailboxes", err)
+	}
+	return sequenceId, nil
+}
+
+// newMailboxWriteError is newAppError, except that an SMTP password that
+// can't be encrypted for lack of a key is reported to the client.
+func newMailboxWriteError(message string, err error) *errors.AppError {
+	if stdErrors.Is(err, secrets.ErrNoKey) {
+		return errors.NewAppError(http.StatusBadRequest, "smtp_password can't be stored: no secrets key is configured", err)
+	}
+	return newAppError(message, err)
+}
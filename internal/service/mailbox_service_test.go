@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"salesforge-api/internal/secrets"
+	"testing"
+)
+
+func TestAddMailbox_Success(t *testing.T) {
+	mockRepo := new(mocks.MailboxRepository)
+	svc := NewMailboxService(mockRepo)
+
+	mailbox := models.Mailbox{
+		AccountID:  1,
+		Email:      "sdr@example.com",
+		SmtpHost:   "smtp.example.com",
+		SmtpPort:   587,
+		DailyLimit: 50,
+	}
+
+	mockRepo.On("AddMailbox", mock.Anything, &mailbox).Return(int64(1), nil)
+
+	ctx := context.Background()
+	mailboxId, err := svc.AddMailbox(ctx, &mailbox)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), mailboxId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddMailbox_NoSecretsKey(t *testing.T) {
+	mockRepo := new(mocks.MailboxRepository)
+	svc := NewMailboxService(mockRepo)
+
+	mailbox := models.Mailbox{AccountID: 1, Email: "sdr@example.com", SmtpPassword: "hunter2"}
+	mockRepo.On("AddMailbox", mock.Anything, &mailbox).Return(int64(0), secrets.ErrNoKey)
+
+	ctx := context.Background()
+	_, err := svc.AddMailbox(ctx, &mailbox)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	assert.Contains(t, appErr.Message, "no secrets key")
+}
+
+func TestListMailboxes_Success(t *testing.T) {
+	mockRepo := new(mocks.MailboxRepository)
+	svc := NewMailboxService(mockRepo)
+
+	list := models.ListMailboxesRequest{AccountID: 1}
+	mailboxes := []models.Mailbox{{AccountID: 1, MailboxID: 1, Email: "sdr@example.com"}}
+
+	mockRepo.On("ListMailboxes", mock.Anything, &list).Return(mailboxes, nil)
+
+	ctx := context.Background()
+	gotMailboxes, err := svc.ListMailboxes(ctx, &list)
+	assert.NoError(t, err)
+	assert.Equal(t, mailboxes, gotMailboxes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetSequenceMailboxes_UnknownMailbox(t *testing.T) {
+	mockRepo := new(mocks.MailboxRepository)
+	svc := NewMailboxService(mockRepo)
+
+	set := models.SetSequenceMailboxesRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		MailboxIDs: []int64{1, 99},
+	}
+
+	mockRepo.On("SetSequenceMailboxes", mock.Anything, &set).Return(int64(0), persistence.ErrValidation)
+
+	ctx := context.Background()
+	sequenceId, err := svc.SetSequenceMailboxes(ctx, &set)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	assert.Equal(t, int64(0), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteMailbox_NotFound(t *testing.T) {
+	mockRepo := new(mocks.MailboxRepository)
+	svc := NewMailboxService(mockRepo)
+
+	delete := models.DeleteMailboxRequest{AccountID: 1, MailboxID: 1}
+
+	mockRepo.On("DeleteMailbox", mock.Anything, &delete).Return(int64(0), persistence.ErrNotFound)
+
+	ctx := context.Background()
+	_, err := svc.DeleteMailbox(ctx, &delete)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/mail"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/schedule"
+	"time"
+)
+
+type previewService struct {
+	sequenceRepo persistence.SequenceRepository
+	contactRepo  persistence.ContactRepository
+	now          func() time.Time
+}
+
+type PreviewService interface {
+	PreviewStep(ctx context.Context, preview *models.PreviewStepRequest) (rendered *mail.Preview, sendAt int64, err error)
+}
+
+func NewPreviewService(
+	sequenceRepo persistence.SequenceRepository,
+	contactRepo persistence.ContactRepository,
+) PreviewService {
+	return &previewService{
+		sequenceRepo: sequenceRepo,
+		contactRepo:  contactRepo,
+		now:          time.Now,
+	}
+}
+
+// PreviewStep renders a step for a stored or unsaved contact and works out
+// when it would be sent if the previous step went out now.
+func (s *previewService) PreviewStep(ctx context.Context, preview *models.PreviewStepRequest) (rendered *mail.Preview, sendAt int64, err error) {
+	if err := auth.CheckAccount(ctx, preview.AccountID); err != nil {
+		return nil, 0, newAppError("failed to preview step", err)
+	}
+
+	step, err := s.sequenceRepo.GetStep(ctx, preview.AccountID, preview.StepID)
+	if err != nil {
+		return nil, 0, newAppError("failed to get step", err)
+	}
+
+	contact := models.Contact{}
+	switch {
+	case preview.ContactID != 0:
+		get := &models.GetContactRequest{
+			AccountID: preview.AccountID,
+			ContactID: preview.ContactID,
+		}
+		stored, err := s.contactRepo.GetContact(ctx, get)
+		if err != nil {
+			return nil, 0, newAppError("failed to get contact", err)
+		}
+		contact = *stored
+	case preview.Contact != nil:
+		contact = *preview.Contact
+	}
+
+	rendered, err = mail.RenderPreview(*step, contact)
+	if err != nil {
+		// Only steps saved before templates were validated can fail here.
+		return nil, 0, sfErr.NewAppError(http.StatusBadRequest, "step template is invalid", err)
+	}
+
+	if at, ok := schedule.SendTime(*step, s.now()); ok {
+		sendAt = at.Unix()
+	}
+	return rendered, sendAt, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"testing"
+	"time"
+)
+
+func TestPreviewStep_StoredContact(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockContactRepo := new(mocks.ContactRepository)
+	svc := NewPreviewService(mockSequenceRepo, mockContactRepo).(*previewService)
+	now := time.Unix(1700000000, 0)
+	svc.now = func() time.Time { return now }
+
+	step := &models.Step{StepID: 2, StepEmailSubject: "Hi {{first_name}}", StepEmailBody: "At {{company}}?", WaitDays: 2}
+	mockSequenceRepo.On("GetStep", mock.Anything, int64(1), int64(2)).Return(step, nil)
+	mockContactRepo.On("GetContact", mock.Anything, &models.GetContactRequest{AccountID: 1, ContactID: 3}).Return(&models.Contact{FirstName: "Jane"}, nil)
+
+	rendered, sendAt, err := svc.PreviewStep(context.Background(), &models.PreviewStepRequest{AccountID: 1, StepID: 2, ContactID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Jane", rendered.Subject)
+	assert.Equal(t, "At ?", rendered.TextBody)
+	assert.Equal(t, []string{"company"}, rendered.MissingVariables)
+	assert.Equal(t, now.Add(48*time.Hour).Unix(), sendAt)
+	mockSequenceRepo.AssertExpectations(t)
+	mockContactRepo.AssertExpectations(t)
+}
+
+func TestPreviewStep_SuppliedContact(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockContactRepo := new(mocks.ContactRepository)
+	svc := NewPreviewService(mockSequenceRepo, mockContactRepo)
+
+	// The window has already closed, so the step would never be sent.
+	step := &models.Step{StepID: 2, StepEmailSubject: "Hi {{first_name}}", StepEmailBody: "Hello", EligibleStartTime: 1, EligibleEndTime: 2}
+	mockSequenceRepo.On("GetStep", mock.Anything, int64(1), int64(2)).Return(step, nil)
+
+	preview := &models.PreviewStepRequest{AccountID: 1, StepID: 2, Contact: &models.Contact{FirstName: "Jon"}}
+	rendered, sendAt, err := svc.PreviewStep(context.Background(), preview)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Jon", rendered.Subject)
+	assert.Zero(t, sendAt)
+	mockContactRepo.AssertNotCalled(t, "GetContact", mock.Anything, mock.Anything)
+}
+
+func TestPreviewStep_NotFound(t *testing.T) {
+	mockSequenceRepo := new(mocks.SequenceRepository)
+	mockContactRepo := new(mocks.ContactRepository)
+	svc := NewPreviewService(mockSequenceRepo, mockContactRepo)
+
+	mockSequenceRepo.On("GetStep", mock.Anything, int64(1), int64(2)).Return(nil, persistence.ErrNotFound)
+
+	_, _, err := svc.PreviewStep(context.Background(), &models.PreviewStepRequest{AccountID: 1, StepID: 2})
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+}
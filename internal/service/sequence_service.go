@@ -2,8 +2,7 @@ package service
 
 import (
 	"context"
-	"net/http"
-	"salesforge-api/internal/errors"
+	"salesforge-api/internal/auth"
 	"salesforge-api/internal/models"
 	"salesforge-api/internal/persistence"
 )
@@ -14,8 +13,15 @@ type sequenceService struct {
 
 type SequenceService interface {
 	AddSequence(ctx context.Context, sequence *models.Sequence, steps *[]models.Step) (sequenceId int64, err error)
+	AddStep(ctx context.Context, add *models.AddStepRequest) (sequenceId int64, stepId int64, stepOrder int, err error)
+	CloneSequence(ctx context.Context, clone *models.CloneSequenceRequest) (accountId int64, sequenceId int64, err error)
+	GetSequence(ctx context.Context, get *models.GetSequenceRequest) (sequence *models.Sequence, steps []models.Step, err error)
+	GetVariantStats(ctx context.Context, get *models.GetVariantStatsRequest) (step *models.Step, stats []models.VariantStats, err error)
+	ListSequences(ctx context.Context, list *models.ListSequencesRequest) (sequences []models.Sequence, next *models.SequenceCursor, err error)
 	UpdateSequence(ctx context.Context, update *models.UpdateSequenceRequest) (sequenceId int64, err error)
+	DeleteSequence(ctx context.Context, delete *models.DeleteSequenceRequest) (sequenceId int64, err error)
 	UpdateStep(ctx context.Context, update *models.UpdateStepRequest) (sequenceId int64, stepId int64, err error)
+	ReorderSteps(ctx context.Context, reorder *models.ReorderStepsRequest) (sequenceId int64, err error)
 	DeleteStep(ctx context.Context, delete *models.DeleteStepRequest) (sequenceId int64, stepId int64, err error)
 }
 
@@ -28,33 +34,139 @@ func NewSequenceService(
 }
 
 func (s *sequenceService) AddSequence(ctx context.Context, sequence *models.Sequence, steps *[]models.Step) (sequenceId int64, err error) {
+	if err := auth.CheckAccount(ctx, sequence.AccountID); err != nil {
+		return 0, newAppError("failed to add sequence", err)
+	}
+
 	sequenceId, err = s.sequenceRepo.AddSequence(ctx, sequence, steps)
 	if err != nil {
-		return 0, errors.NewAppError(http.StatusInternalServerError, "failed to add sequence", err)
+		return 0, newAppError("failed to add sequence", err)
 	}
 	return sequenceId, nil
 }
 
+func (s *sequenceService) AddStep(ctx context.Context, add *models.AddStepRequest) (sequenceId int64, stepId int64, stepOrder int, err error) {
+	if err := auth.CheckAccount(ctx, add.AccountID); err != nil {
+		return 0, 0, 0, newAppError("failed to add step", err)
+	}
+
+	sequenceId, stepId, stepOrder, err = s.sequenceRepo.AddStep(ctx, add)
+	if err != nil {
+		return 0, 0, 0, newAppError("failed to add step", err)
+	}
+	return sequenceId, stepId, stepOrder, nil
+}
+
+// CloneSequence only clones across accounts for unauthenticated callers, as
+// a token is scoped to a single account.
+func (s *sequenceService) CloneSequence(ctx context.Context, clone *models.CloneSequenceRequest) (accountId int64, sequenceId int64, err error) {
+	accountIds := []int64{clone.AccountID}
+	if clone.TargetAccountID != 0 {
+		accountIds = append(accountIds, clone.TargetAccountID)
+	}
+	if err := auth.CheckAccount(ctx, accountIds...); err != nil {
+		return 0, 0, newAppError("failed to clone sequence", err)
+	}
+
+	accountId, sequenceId, err = s.sequenceRepo.CloneSequence(ctx, clone)
+	if err != nil {
+		return 0, 0, newAppError("failed to clone sequence", err)
+	}
+	return accountId, sequenceId, nil
+}
+
+func (s *sequenceService) GetSequence(ctx context.Context, get *models.GetSequenceRequest) (sequence *models.Sequence, steps []models.Step, err error) {
+	if err := auth.CheckAccount(ctx, get.AccountID); err != nil {
+		return nil, nil, newAppError("failed to get sequence", err)
+	}
+
+	sequence, steps, err = s.sequenceRepo.GetSequence(ctx, get)
+	if err != nil {
+		return nil, nil, newAppError("failed to get sequence", err)
+	}
+	return sequence, steps, nil
+}
+
+func (s *sequenceService) GetVariantStats(ctx context.Context, get *models.GetVariantStatsRequest) (step *models.Step, stats []models.VariantStats, err error) {
+	if err := auth.CheckAccount(ctx, get.AccountID); err != nil {
+		return nil, nil, newAppError("failed to get variant stats", err)
+	}
+
+	step, stats, err = s.sequenceRepo.GetVariantStats(ctx, get)
+	if err != nil {
+		return nil, nil, newAppError("failed to get variant stats", err)
+	}
+	return step, stats, nil
+}
+
+func (s *sequenceService) ListSequences(ctx context.Context, list *models.ListSequencesRequest) (sequences []models.Sequence, next *models.SequenceCursor, err error) {
+	if err := auth.CheckAccount(ctx, list.AccountID); err != nil {
+		return nil, nil, newAppError("failed to list sequences", err)
+	}
+
+	sequences, next, err = s.sequenceRepo.ListSequences(ctx, list)
+	if err != nil {
+		return nil, nil, newAppError("failed to list sequences", err)
+	}
+	return sequences, next, nil
+}
+
 func (s *sequenceService) UpdateSequence(ctx context.Context, update *models.UpdateSequenceRequest) (sequenceId int64, err error) {
+	if err := auth.CheckAccount(ctx, update.AccountID); err != nil {
+		return 0, newAppError("failed to update sequence", err)
+	}
+
 	sequenceId, err = s.sequenceRepo.UpdateSequence(ctx, update)
 	if err != nil {
-		return 0, errors.NewAppError(http.StatusInternalServerError, "failed to update sequence", err)
+		return 0, newAppError("failed to update sequence", err)
+	}
+	return sequenceId, nil
+}
+
+func (s *sequenceService) DeleteSequence(ctx context.Context, delete *models.DeleteSequenceRequest) (sequenceId int64, err error) {
+	if err := auth.CheckAccount(ctx, delete.AccountID); err != nil {
+		return 0, newAppError("failed to delete sequence", err)
+	}
+
+	sequenceId, err = s.sequenceRepo.DeleteSequence(ctx, delete)
+	if err != nil {
+		return 0, newAppError("failed to delete sequence", err)
 	}
 	return sequenceId, nil
 }
 
 func (s *sequenceService) UpdateStep(ctx context.Context, update *models.UpdateStepRequest) (sequenceId int64, stepId int64, err error) {
+	if err := auth.CheckAccount(ctx, update.AccountID); err != nil {
+		return 0, 0, newAppError("failed to update step", err)
+	}
+
 	sequenceId, stepId, err = s.sequenceRepo.UpdateStep(ctx, update)
 	if err != nil {
-		return 0, 0, errors.NewAppError(http.StatusInternalServerError, "failed to update step", err)
+		return 0, 0, newAppError("failed to update step", err)
 	}
 	return sequenceId, stepId, nil
 }
 
+func (s *sequenceService) ReorderSteps(ctx context.Context, reorder *models.ReorderStepsRequest) (sequenceId int64, err error) {
+	if err := auth.CheckAccount(ctx, reorder.AccountID); err != nil {
+		return 0, newAppError("failed to reorder steps", err)
+	}
+
+	sequenceId, err = s.sequenceRepo.ReorderSteps(ctx, reorder)
+	if err != nil {
+		return 0, newAppError("failed to reorder steps", err)
+	}
+	return sequenceId, nil
+}
+
 func (s *sequenceService) DeleteStep(ctx context.Context, delete *models.DeleteStepRequest) (sequenceId int64, stepId int64, err error) {
+	if err := auth.CheckAccount(ctx, delete.AccountID); err != nil {
+		return 0, 0, newAppError("failed to delete step", err)
+	}
+
 	sequenceId, stepId, err = s.sequenceRepo.DeleteStep(ctx, delete)
 	if err != nil {
-		return 0, 0, errors.NewAppError(http.StatusInternalServerError, "failed to delete step", err)
+		return 0, 0, newAppError("failed to delete step", err)
 	}
 	return sequenceId, stepId, nil
 }
@@ -5,7 +5,11 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"net/http"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
 	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
 	"salesforge-api/internal/persistence/mocks"
 	"testing"
 )
@@ -90,8 +94,8 @@ func TestUpdateStep(t *testing.T) {
 		AccountID:        1,
 		SequenceID:       1,
 		StepID:           1,
-		StepEmailSubject: "Updated Subject",
-		StepEmailBody:    "Updated Body",
+		StepEmailSubject: &[]string{"Updated Subject"}[0],
+		StepEmailBody:    &[]string{"Updated Body"}[0],
 	}
 
 	mockRepo.On("UpdateStep", mock.Anything, mock.Anything).Return(int64(1), int64(1), nil)
@@ -111,8 +115,8 @@ func TestUpdateStep_InvalidInput(t *testing.T) {
 		AccountID:        -1, // Invalid AccountID
 		SequenceID:       1,
 		StepID:           1,
-		StepEmailSubject: "",
-		StepEmailBody:    "",
+		StepEmailSubject: &[]string{""}[0],
+		StepEmailBody:    &[]string{""}[0],
 	}
 
 	// Expect no call to UpdateStep due to validation failure
@@ -270,8 +274,8 @@ func TestUpdateStep_Success(t *testing.T) {
 		AccountID:        1,
 		SequenceID:       1,
 		StepID:           1,
-		StepEmailSubject: "Updated Subject",
-		StepEmailBody:    "Updated Body",
+		StepEmailSubject: &[]string{"Updated Subject"}[0],
+		StepEmailBody:    &[]string{"Updated Body"}[0],
 	}
 
 	mockRepo.On("UpdateStep", mock.Anything, &update).Return(int64(1), int64(1), nil)
@@ -292,8 +296,8 @@ func TestUpdateStep_Failure(t *testing.T) {
 		AccountID:        1,
 		SequenceID:       1,
 		StepID:           1,
-		StepEmailSubject: "Updated Subject",
-		StepEmailBody:    "Updated Body",
+		StepEmailSubject: &[]string{"Updated Subject"}[0],
+		StepEmailBody:    &[]string{"Updated Body"}[0],
 	}
 
 	mockRepo.On("UpdateStep", mock.Anything, &update).Return(int64(0), int64(0), errors.New("db error"))
@@ -345,3 +349,371 @@ func TestDeleteStep_Failure(t *testing.T) {
 	assert.Equal(t, int64(0), stepId)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetSequence_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	get := models.GetSequenceRequest{
+		AccountID:  1,
+		SequenceID: 1,
+	}
+	sequence := models.Sequence{
+		AccountID:    1,
+		SequenceID:   1,
+		SequenceName: "Test Sequence",
+	}
+	steps := []models.Step{
+		{
+			StepID:           1,
+			SequenceID:       1,
+			StepEmailSubject: "Subject 1",
+			StepEmailBody:    "Body 1",
+		},
+	}
+
+	mockRepo.On("GetSequence", mock.Anything, &get).Return(&sequence, steps, nil)
+
+	ctx := context.Background()
+	gotSequence, gotSteps, err := svc.GetSequence(ctx, &get)
+	assert.NoError(t, err)
+	assert.Equal(t, &sequence, gotSequence)
+	assert.Equal(t, steps, gotSteps)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSequence_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	get := models.GetSequenceRequest{
+		AccountID:  1,
+		SequenceID: 1,
+	}
+
+	mockRepo.On("GetSequence", mock.Anything, &get).Return(nil, nil, errors.New("db error"))
+
+	ctx := context.Background()
+	sequence, steps, err := svc.GetSequence(ctx, &get)
+	assert.Error(t, err)
+	assert.Nil(t, sequence)
+	assert.Nil(t, steps)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetVariantStats_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	get := models.GetVariantStatsRequest{
+		AccountID: 1,
+		StepID:    1,
+	}
+	step := models.Step{StepID: 1, AutoPromoteAfter: 100}
+	stats := []models.VariantStats{
+		{VariantID: 1, StepEmailSubject: "A", Weight: 1, Sends: 10, Opens: 5},
+		{VariantID: 2, StepEmailSubject: "B", Weight: 1, Sends: 12, Opens: 3},
+	}
+
+	mockRepo.On("GetVariantStats", mock.Anything, &get).Return(&step, stats, nil)
+
+	ctx := context.Background()
+	gotStep, gotStats, err := svc.GetVariantStats(ctx, &get)
+	assert.NoError(t, err)
+	assert.Equal(t, &step, gotStep)
+	assert.Equal(t, stats, gotStats)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListSequences_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	list := models.ListSequencesRequest{
+		AccountID: 1,
+		SortBy:    models.SequenceSortByCreatedAt,
+		SortOrder: models.SortOrderDesc,
+		Limit:     1,
+	}
+	sequences := []models.Sequence{
+		{
+			AccountID:    1,
+			SequenceID:   2,
+			CreatedAt:    1706132001,
+			SequenceName: "Test Sequence",
+		},
+	}
+	next := &models.SequenceCursor{
+		SortBy:     models.SequenceSortByCreatedAt,
+		SortOrder:  models.SortOrderDesc,
+		CreatedAt:  1706132001,
+		SequenceID: 2,
+	}
+
+	mockRepo.On("ListSequences", mock.Anything, &list).Return(sequences, next, nil)
+
+	ctx := context.Background()
+	gotSequences, gotNext, err := svc.ListSequences(ctx, &list)
+	assert.NoError(t, err)
+	assert.Equal(t, sequences, gotSequences)
+	assert.Equal(t, next, gotNext)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListSequences_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	list := models.ListSequencesRequest{
+		AccountID: 1,
+		SortBy:    models.SequenceSortByCreatedAt,
+		SortOrder: models.SortOrderDesc,
+		Limit:     1,
+	}
+
+	mockRepo.On("ListSequences", mock.Anything, &list).Return(nil, nil, errors.New("db error"))
+
+	ctx := context.Background()
+	sequences, next, err := svc.ListSequences(ctx, &list)
+	assert.Error(t, err)
+	assert.Nil(t, sequences)
+	assert.Nil(t, next)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddStep_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	add := models.AddStepRequest{
+		AccountID:         1,
+		SequenceID:        1,
+		Position:          2,
+		StepEmailSubject:  "Subject 2",
+		StepEmailBody:     "Body 2",
+		WaitDays:          1,
+		EligibleStartTime: 1717758001,
+		EligibleEndTime:   1718758034,
+	}
+
+	mockRepo.On("AddStep", mock.Anything, &add).Return(int64(1), int64(3), 2, nil)
+
+	ctx := context.Background()
+	sequenceId, stepId, stepOrder, err := svc.AddStep(ctx, &add)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), sequenceId)
+	assert.Equal(t, int64(3), stepId)
+	assert.Equal(t, 2, stepOrder)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddStep_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	add := models.AddStepRequest{
+		AccountID:         1,
+		SequenceID:        1,
+		StepEmailSubject:  "Subject 2",
+		StepEmailBody:     "Body 2",
+		WaitDays:          1,
+		EligibleStartTime: 1717758001,
+		EligibleEndTime:   1718758034,
+	}
+
+	mockRepo.On("AddStep", mock.Anything, &add).Return(int64(0), int64(0), 0, errors.New("db error"))
+
+	ctx := context.Background()
+	sequenceId, stepId, stepOrder, err := svc.AddStep(ctx, &add)
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), sequenceId)
+	assert.Equal(t, int64(0), stepId)
+	assert.Equal(t, 0, stepOrder)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReorderSteps_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	reorder := models.ReorderStepsRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		StepIDs:    []int64{1, 3, 2},
+	}
+
+	mockRepo.On("ReorderSteps", mock.Anything, &reorder).Return(int64(1), nil)
+
+	ctx := context.Background()
+	sequenceId, err := svc.ReorderSteps(ctx, &reorder)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReorderSteps_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	reorder := models.ReorderStepsRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		StepIDs:    []int64{1, 3, 2},
+	}
+
+	mockRepo.On("ReorderSteps", mock.Anything, &reorder).Return(int64(0), errors.New("db error"))
+
+	ctx := context.Background()
+	sequenceId, err := svc.ReorderSteps(ctx, &reorder)
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteSequence_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	delete := models.DeleteSequenceRequest{
+		AccountID:  1,
+		SequenceID: 1,
+		Archive:    true,
+	}
+
+	mockRepo.On("DeleteSequence", mock.Anything, &delete).Return(int64(1), nil)
+
+	ctx := context.Background()
+	sequenceId, err := svc.DeleteSequence(ctx, &delete)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteSequence_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	delete := models.DeleteSequenceRequest{
+		AccountID:  1,
+		SequenceID: 1,
+	}
+
+	mockRepo.On("DeleteSequence", mock.Anything, &delete).Return(int64(0), errors.New("db error"))
+
+	ctx := context.Background()
+	sequenceId, err := svc.DeleteSequence(ctx, &delete)
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCloneSequence_Success(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	clone := models.CloneSequenceRequest{
+		AccountID:       1,
+		SequenceID:      1,
+		TargetAccountID: 2,
+	}
+
+	mockRepo.On("CloneSequence", mock.Anything, &clone).Return(int64(2), int64(5), nil)
+
+	ctx := context.Background()
+	accountId, sequenceId, err := svc.CloneSequence(ctx, &clone)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), accountId)
+	assert.Equal(t, int64(5), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCloneSequence_Failure(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	clone := models.CloneSequenceRequest{
+		AccountID:  1,
+		SequenceID: 1,
+	}
+
+	mockRepo.On("CloneSequence", mock.Anything, &clone).Return(int64(0), int64(0), errors.New("db error"))
+
+	ctx := context.Background()
+	accountId, sequenceId, err := svc.CloneSequence(ctx, &clone)
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), accountId)
+	assert.Equal(t, int64(0), sequenceId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteStep_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", persistence.ErrNotFound, http.StatusNotFound},
+		{"conflict", persistence.ErrConflict, http.StatusConflict},
+		{"validation", persistence.ErrValidation, http.StatusBadRequest},
+		{"other", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.SequenceRepository)
+			svc := NewSequenceService(mockRepo)
+
+			delete := models.DeleteStepRequest{
+				AccountID:  1,
+				SequenceID: 1,
+				StepID:     1,
+			}
+
+			mockRepo.On("DeleteStep", mock.Anything, &delete).Return(int64(0), int64(0), tt.err)
+
+			ctx := context.Background()
+			_, _, err := svc.DeleteStep(ctx, &delete)
+			var appErr *sfErr.AppError
+			assert.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.code, appErr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateStep_OtherAccount(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	update := models.UpdateStepRequest{
+		AccountID:  2,
+		StepID:     1,
+		SequenceID: 1,
+		WaitDays:   &[]int{3}[0],
+	}
+
+	ctx := auth.NewContext(context.Background(), &auth.Identity{AccountID: 1})
+	_, _, err := svc.UpdateStep(ctx, &update)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusForbidden, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateStep", mock.Anything, mock.Anything)
+}
+
+func TestCloneSequence_OtherAccount(t *testing.T) {
+	mockRepo := new(mocks.SequenceRepository)
+	svc := NewSequenceService(mockRepo)
+
+	clone := models.CloneSequenceRequest{
+		AccountID:       1,
+		SequenceID:      1,
+		TargetAccountID: 2,
+	}
+
+	ctx := auth.NewContext(context.Background(), &auth.Identity{AccountID: 1})
+	_, _, err := svc.CloneSequence(ctx, &clone)
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusForbidden, appErr.Code)
+	mockRepo.AssertNotCalled(t, "CloneSequence", mock.Anything, mock.Anything)
+}
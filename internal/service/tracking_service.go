@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"salesforge-api/internal/auth"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/tracking"
+)
+
+type trackingService struct {
+	eventRepo persistence.EventRepository
+	tracker   *tracking.Tracker
+}
+
+type TrackingService interface {
+	TrackOpen(ctx context.Context, token string, userAgent string, ipAddress string) (eventId int64, err error)
+	TrackClick(ctx context.Context, token string, userAgent string, ipAddress string) (url string, err error)
+	RecordReply(ctx context.Context, request *models.AddReplyRequest) (eventId int64, err error)
+}
+
+func NewTrackingService(
+	eventRepo persistence.EventRepository,
+	tracker *tracking.Tracker,
+) TrackingService {
+	return &trackingService{
+		eventRepo: eventRepo,
+		tracker:   tracker,
+	}
+}
+
+func newInvalidTokenError(err error) error {
+	return sfErr.NewAppError(http.StatusNotFound, "tracking link not found", err)
+}
+
+// TrackOpen records an open of the send the pixel token was signed for.
+func (s *trackingService) TrackOpen(ctx context.Context, token string, userAgent string, ipAddress string) (eventId int64, err error) {
+	var t tracking.Token
+	if err := s.tracker.Verify(tracking.PurposeOpen, token, &t); err != nil {
+		return 0, newInvalidTokenError(err)
+	}
+
+	event := &models.Event{
+		AccountID:    t.AccountID,
+		SequenceID:   t.SequenceID,
+		EnrollmentID: t.EnrollmentID,
+		StepID:       t.StepID,
+		Type:         models.EventTypeOpen,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
+	}
+	eventId, err = s.eventRepo.AddEvent(ctx, event)
+	if err != nil {
+		return 0, newAppError("failed to record open", err)
+	}
+	return eventId, nil
+}
+
+// TrackClick records a click of the link the token was signed for and
+// returns its URL. The URL is returned even when recording fails, so the
+// recipient still gets to the link; it is empty only for invalid tokens.
+func (s *trackingService) TrackClick(ctx context.Context, token string, userAgent string, ipAddress string) (url string, err error) {
+	var t tracking.ClickToken
+	if err := s.tracker.Verify(tracking.PurposeClick, token, &t); err != nil {
+		return "", newInvalidTokenError(err)
+	}
+	if !tracking.Trackable(t.URL) {
+		return "", newInvalidTokenError(tracking.ErrInvalidToken)
+	}
+
+	event := &models.Event{
+		AccountID:    t.AccountID,
+		SequenceID:   t.SequenceID,
+		EnrollmentID: t.EnrollmentID,
+		StepID:       t.StepID,
+		Type:         models.EventTypeClick,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
+		URL:          t.URL,
+	}
+	_, err = s.eventRepo.AddEvent(ctx, event)
+	if err != nil {
+		return t.URL, newAppError("failed to record click", err)
+	}
+	return t.URL, nil
+}
+
+// RecordReply records a reply to the send whose message it answers. Replies
+// are reported by whatever reads the mailboxes, as they don't go through
+// the API.
+func (s *trackingService) RecordReply(ctx context.Context, request *models.AddReplyRequest) (eventId int64, err error) {
+	if err := auth.CheckAccount(ctx, request.AccountID); err != nil {
+		return 0, newAppError("failed to record reply", err)
+	}
+
+	eventId, err = s.eventRepo.AddReply(ctx, request.AccountID, request.MessageID())
+	if err != nil {
+		return 0, newAppError("failed to record reply", err)
+	}
+	return eventId, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"salesforge-api/internal/auth"
+	"salesforge-api/internal/config"
+	sfErr "salesforge-api/internal/errors"
+	"salesforge-api/internal/models"
+	"salesforge-api/internal/persistence"
+	"salesforge-api/internal/persistence/mocks"
+	"salesforge-api/internal/tracking"
+	"testing"
+)
+
+var testTracker = tracking.NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"})
+
+func TestTrackOpen_Success(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	token, err := testTracker.Sign(tracking.PurposeOpen, tracking.Token{AccountID: 1, SequenceID: 2, EnrollmentID: 3, StepID: 4})
+	assert.NoError(t, err)
+
+	event := &models.Event{
+		AccountID:    1,
+		SequenceID:   2,
+		EnrollmentID: 3,
+		StepID:       4,
+		Type:         models.EventTypeOpen,
+		UserAgent:    "Mozilla/5.0",
+		IPAddress:    "203.0.113.7",
+	}
+	mockRepo.On("AddEvent", mock.Anything, event).Return(int64(1), nil)
+
+	ctx := context.Background()
+	eventId, err := svc.TrackOpen(ctx, token, "Mozilla/5.0", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), eventId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrackOpen_InvalidToken(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	ctx := context.Background()
+	_, err := svc.TrackOpen(ctx, "forged.token", "Mozilla/5.0", "203.0.113.7")
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	mockRepo.AssertNotCalled(t, "AddEvent", mock.Anything, mock.Anything)
+}
+
+func TestTrackOpen_RejectsClickToken(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	token, err := testTracker.Sign(tracking.PurposeClick, tracking.ClickToken{
+		Token: tracking.Token{AccountID: 1, SequenceID: 2, EnrollmentID: 3, StepID: 4},
+		URL:   "https://example.com/pricing",
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = svc.TrackOpen(ctx, token, "Mozilla/5.0", "203.0.113.7")
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	mockRepo.AssertNotCalled(t, "AddEvent", mock.Anything, mock.Anything)
+}
+
+func TestTrackClick_Success(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	token, err := testTracker.Sign(tracking.PurposeClick, tracking.ClickToken{
+		Token: tracking.Token{AccountID: 1, SequenceID: 2, EnrollmentID: 3, StepID: 4},
+		URL:   "https://example.com/pricing",
+	})
+	assert.NoError(t, err)
+
+	mockRepo.On("AddEvent", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventTypeClick && e.URL == "https://example.com/pricing" && e.EnrollmentID == 3
+	})).Return(int64(1), nil)
+
+	ctx := context.Background()
+	url, err := svc.TrackClick(ctx, token, "Mozilla/5.0", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/pricing", url)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrackClick_RecordFailureStillRedirects(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	token, err := testTracker.Sign(tracking.PurposeClick, tracking.ClickToken{URL: "https://example.com"})
+	assert.NoError(t, err)
+
+	mockRepo.On("AddEvent", mock.Anything, mock.Anything).Return(int64(0), errors.New("connection refused"))
+
+	ctx := context.Background()
+	url, err := svc.TrackClick(ctx, token, "", "")
+	assert.Error(t, err)
+	assert.Equal(t, "https://example.com", url)
+}
+
+func TestTrackClick_RejectsUntrackedURL(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	token, err := testTracker.Sign(tracking.PurposeClick, tracking.ClickToken{URL: "javascript:alert(1)"})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	url, err := svc.TrackClick(ctx, token, "", "")
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+	assert.Empty(t, url)
+	mockRepo.AssertNotCalled(t, "AddEvent", mock.Anything, mock.Anything)
+}
+
+func TestRecordReply_Success(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	mockRepo.On("AddReply", mock.Anything, int64(1), "abc@sales.example.com").Return(int64(7), nil)
+
+	ctx := context.Background()
+	eventId, err := svc.RecordReply(ctx, &models.AddReplyRequest{AccountID: 1, InReplyTo: " <abc@sales.example.com>"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), eventId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordReply_UnknownMessage(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	mockRepo.On("AddReply", mock.Anything, int64(1), "unknown@example.com").Return(int64(0), persistence.ErrNotFound)
+
+	ctx := context.Background()
+	_, err := svc.RecordReply(ctx, &models.AddReplyRequest{AccountID: 1, InReplyTo: "unknown@example.com"})
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+}
+
+func TestRecordReply_OtherAccount(t *testing.T) {
+	mockRepo := new(mocks.EventRepository)
+	svc := NewTrackingService(mockRepo, testTracker)
+
+	ctx := auth.NewContext(context.Background(), &auth.Identity{AccountID: 1})
+	_, err := svc.RecordReply(ctx, &models.AddReplyRequest{AccountID: 2, InReplyTo: "abc@sales.example.com"})
+	var appErr *sfErr.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusForbidden, appErr.Code)
+	mockRepo.AssertNotCalled(t, "AddReply", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,19 @@
+package templating
+
+import (
+	"salesforge-api/internal/models"
+)
+
+// ContactVariables are the values of every variable for contact.
+func ContactVariables(contact models.Contact) map[string]string {
+	vars := map[string]string{
+		"first_name": contact.FirstName,
+		"last_name":  contact.LastName,
+		"company":    contact.Company,
+		"email":      contact.Email,
+	}
+	for k, v := range contact.CustomFields {
+		vars[CustomFieldPrefix+k] = v
+	}
+	return vars
+}
@@ -0,0 +1,232 @@
+// Package templating renders the personalization tags of step subjects and
+// bodies. Tags are written between double braces:
+//
+//	{{first_name}}                      a contact variable
+//	{{first_name | default:"there"}}    with a fallback when it is empty
+//	{{custom.title}}                    a custom contact field
+//	{{#if company}}at {{company}}{{else}}there{{/if}}
+//
+// A conditional renders its first branch when the variable is not empty.
+package templating
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CustomFieldPrefix namespaces custom contact fields, which can have any
+// name, from the built in variables.
+const CustomFieldPrefix = "custom."
+
+// Variables are the built in variables every contact has.
+var Variables = []string{"first_name", "last_name", "company", "email"}
+
+var variableName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$|^custom\.[A-Za-z0-9_-]+$`)
+
+// ParseError is a syntax error or unknown variable, at Pos bytes into the
+// template.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+type node interface{}
+
+type textNode string
+
+type varNode struct {
+	name     string
+	fallback *string
+}
+
+type ifNode struct {
+	name      string
+	then, els []node
+}
+
+type Template struct {
+	nodes []node
+}
+
+func known(name string) bool {
+	if strings.HasPrefix(name, CustomFieldPrefix) {
+		return variableName.MatchString(name)
+	}
+	for _, v := range Variables {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses src, rejecting syntax errors and unknown variables.
+func Parse(src string) (*Template, error) {
+	type block struct {
+		pos    int
+		node   *ifNode
+		inElse bool
+		parent *[]node
+	}
+	var stack []*block
+	root := []node{}
+	current := &root
+
+	pos := 0
+	for pos < len(src) {
+		start := strings.Index(src[pos:], "{{")
+		if start < 0 {
+			*current = append(*current, textNode(src[pos:]))
+			break
+		}
+		start += pos
+		if start > pos {
+			*current = append(*current, textNode(src[pos:start]))
+		}
+		end := strings.Index(src[start+2:], "}}")
+		if end < 0 {
+			return nil, &ParseError{Pos: start, Msg: "unclosed tag"}
+		}
+		end += start + 2
+		tag := strings.TrimSpace(src[start+2 : end])
+		pos = end + 2
+
+		switch {
+		case strings.HasPrefix(tag, "#if"):
+			name := strings.TrimSpace(strings.TrimPrefix(tag, "#if"))
+			if err := checkVariable(name, start); err != nil {
+				return nil, err
+			}
+			n := &ifNode{name: name}
+			*current = append(*current, n)
+			stack = append(stack, &block{pos: start, node: n, parent: current})
+			current = &n.then
+		case tag == "else":
+			if len(stack) == 0 || stack[len(stack)-1].inElse {
+				return nil, &ParseError{Pos: start, Msg: "else without if"}
+			}
+			b := stack[len(stack)-1]
+			b.inElse = true
+			current = &b.node.els
+		case tag == "/if":
+			if len(stack) == 0 {
+				return nil, &ParseError{Pos: start, Msg: "/if without if"}
+			}
+			current = stack[len(stack)-1].parent
+			stack = stack[:len(stack)-1]
+		default:
+			n, err := parseVariable(tag, start)
+			if err != nil {
+				return nil, err
+			}
+			*current = append(*current, n)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, &ParseError{Pos: stack[len(stack)-1].pos, Msg: "unclosed #if"}
+	}
+
+	return &Template{nodes: root}, nil
+}
+
+func checkVariable(name string, pos int) error {
+	if name == "" {
+		return &ParseError{Pos: pos, Msg: "missing variable name"}
+	}
+	if !known(name) {
+		return &ParseError{Pos: pos, Msg: fmt.Sprintf("unknown variable %q", name)}
+	}
+	return nil
+}
+
+// parseVariable parses "name" or "name | default:\"fallback\"".
+func parseVariable(tag string, pos int) (*varNode, error) {
+	name, filter, hasFilter := strings.Cut(tag, "|")
+	name = strings.TrimSpace(name)
+	if err := checkVariable(name, pos); err != nil {
+		return nil, err
+	}
+	n := &varNode{name: name}
+	if !hasFilter {
+		return n, nil
+	}
+
+	filter = strings.TrimSpace(filter)
+	arg, ok := strings.CutPrefix(filter, "default:")
+	if !ok {
+		return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("unknown filter %q", filter)}
+	}
+	fallback, err := strconv.Unquote(strings.TrimSpace(arg))
+	if err != nil {
+		return nil, &ParseError{Pos: pos, Msg: "default needs a quoted value"}
+	}
+	n.fallback = &fallback
+	return n, nil
+}
+
+// Validate reports the first syntax error or unknown variable in src.
+func Validate(src string) error {
+	_, err := Parse(src)
+	return err
+}
+
+// Execute renders the template with vars, passing every substituted value
+// through escape. missing lists the variables that were rendered empty for
+// lack of a value and a fallback.
+func (t *Template) Execute(vars map[string]string, escape func(string) string) (out string, missing []string) {
+	var sb strings.Builder
+	missingSet := map[string]bool{}
+	execute(&sb, t.nodes, vars, escape, missingSet)
+
+	for name := range missingSet {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return sb.String(), missing
+}
+
+func execute(sb *strings.Builder, nodes []node, vars map[string]string, escape func(string) string, missing map[string]bool) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case textNode:
+			sb.WriteString(string(n))
+		case *varNode:
+			value := vars[n.name]
+			if value == "" && n.fallback != nil {
+				value = *n.fallback
+			}
+			if value == "" {
+				missing[n.name] = true
+			}
+			sb.WriteString(escape(value))
+		case *ifNode:
+			if strings.TrimSpace(vars[n.name]) != "" {
+				execute(sb, n.then, vars, escape, missing)
+			} else {
+				execute(sb, n.els, vars, escape, missing)
+			}
+		}
+	}
+}
+
+// Render parses and executes src in one go.
+func Render(src string, vars map[string]string, escape func(string) string) (out string, missing []string, err error) {
+	t, err := Parse(src)
+	if err != nil {
+		return "", nil, err
+	}
+	out, missing = t.Execute(vars, escape)
+	return out, missing, nil
+}
+
+// NoEscape leaves values as they are, for plain text.
+func NoEscape(s string) string {
+	return s
+}
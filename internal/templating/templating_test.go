@@ -0,0 +1,68 @@
+package templating
+
+import (
+	"github.com/stretchr/testify/assert"
+	"html"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	vars := map[string]string{"first_name": "Jane", "company": "Acme", "custom.title": "CTO"}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		missing []string
+	}{
+		{"text", "Hello!", "Hello!", nil},
+		{"variables", "Hi {{first_name}} at {{ company }}", "Hi Jane at Acme", nil},
+		{"custom field", "Hi {{custom.title}}", "Hi CTO", nil},
+		{"missing", "Hi {{last_name}}!", "Hi !", []string{"last_name"}},
+		{"default", `Hi {{last_name | default:"there"}}!`, "Hi there!", nil},
+		{"default unused", `Hi {{first_name | default:"there"}}!`, "Hi Jane!", nil},
+		{"if", "{{#if company}}at {{company}}{{/if}}.", "at Acme.", nil},
+		{"else", "{{#if last_name}}{{last_name}}{{else}}friend{{/if}}", "friend", nil},
+		{"nested", "{{#if company}}{{#if custom.team}}team{{else}}co{{/if}}{{/if}}", "co", nil},
+		{"missing in untaken branch", "{{#if company}}ok{{else}}{{last_name}}{{/if}}", "ok", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, missing, err := Render(tt.src, vars, NoEscape)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+			assert.Equal(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestRender_Escape(t *testing.T) {
+	out, _, err := Render("<b>{{company}}</b>", map[string]string{"company": "<Smith & Sons>"}, html.EscapeString)
+	assert.NoError(t, err)
+	assert.Equal(t, "<b>&lt;Smith &amp; Sons&gt;</b>", out)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"Hi {{first_name", "unclosed tag at position 3"},
+		{"Hi {{fist_name}}", `unknown variable "fist_name" at position 3`},
+		{"Hi {{}}", "missing variable name at position 3"},
+		{"Hi {{first_name | upper}}", `unknown filter "upper" at position 3`},
+		{"Hi {{first_name | default:there}}", "default needs a quoted value at position 3"},
+		{"{{#if company}}x", "unclosed #if at position 0"},
+		{"x{{/if}}", "/if without if at position 1"},
+		{"{{else}}", "else without if at position 0"},
+		{"{{#if company}}a{{else}}b{{else}}c{{/if}}", "else without if at position 25"},
+		{"{{custom.}}", `unknown variable "custom." at position 0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			assert.EqualError(t, Validate(tt.src), tt.err)
+		})
+	}
+
+	assert.NoError(t, Validate(`{{#if custom.title}}{{custom.title}}{{else}}{{email | default:"x"}}{{/if}}`))
+}
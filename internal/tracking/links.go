@@ -0,0 +1,84 @@
+package tracking
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ClickToken identifies the send a click tracking URL was generated for and
+// the URL it redirects to.
+type ClickToken struct {
+	Token
+	URL string `json:"url"`
+}
+
+var (
+	hrefAttr = regexp.MustCompile(`(?i)(\bhref\s*=\s*)("[^"]*"|'[^']*')`)
+	textURL  = regexp.MustCompile(`https?://[^\s<>"]+`)
+)
+
+// Trackable reports whether a link can be redirected through click
+// tracking. Only absolute http and https URLs can; mailto, anchors and the
+// like are left alone.
+func Trackable(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ClickURL is the URL that records a click for token and redirects to link.
+func (t *Tracker) ClickURL(token Token, link string) (string, error) {
+	signed, err := t.Sign(PurposeClick, ClickToken{Token: token, URL: link})
+	if err != nil {
+		return "", err
+	}
+	return t.baseURL + "/t/c/" + signed, nil
+}
+
+// RewriteHTMLLinks points every trackable href of an HTML body to its click
+// tracking URL.
+func (t *Tracker) RewriteHTMLLinks(body string, token Token) (string, error) {
+	var err error
+	rewritten := hrefAttr.ReplaceAllStringFunc(body, func(attr string) string {
+		m := hrefAttr.FindStringSubmatch(attr)
+		quote := m[2][:1]
+		link := html.UnescapeString(strings.TrimSpace(m[2][1 : len(m[2])-1]))
+		if !Trackable(link) {
+			return attr
+		}
+		clickURL, signErr := t.ClickURL(token, link)
+		if signErr != nil {
+			err = signErr
+			return attr
+		}
+		return m[1] + quote + clickURL + quote
+	})
+	if err != nil {
+		return "", err
+	}
+	return rewritten, nil
+}
+
+// RewriteTextLinks replaces every http and https URL of a plain text body
+// with its click tracking URL. Punctuation ending a sentence is not taken
+// as part of the URL.
+func (t *Tracker) RewriteTextLinks(body string, token Token) (string, error) {
+	var err error
+	rewritten := textURL.ReplaceAllStringFunc(body, func(link string) string {
+		trimmed := strings.TrimRight(link, ".,;:!?)]}'")
+		if !Trackable(trimmed) {
+			return link
+		}
+		clickURL, signErr := t.ClickURL(token, trimmed)
+		if signErr != nil {
+			err = signErr
+			return link
+		}
+		return clickURL + link[len(trimmed):]
+	})
+	if err != nil {
+		return "", err
+	}
+	return rewritten, nil
+}
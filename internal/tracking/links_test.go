@@ -0,0 +1,56 @@
+package tracking
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"salesforge-api/internal/config"
+	"testing"
+)
+
+var clickURLPattern = regexp.MustCompile(`https://t\.example\.com/t/c/([A-Za-z0-9_-]+\.[A-Za-z0-9_-]+)`)
+
+// clickTargets verifies every click tracking URL in body and returns the
+// links they redirect to.
+func clickTargets(t *testing.T, tracker *Tracker, body string) []string {
+	var targets []string
+	for _, m := range clickURLPattern.FindAllStringSubmatch(body, -1) {
+		var token ClickToken
+		assert.NoError(t, tracker.Verify(PurposeClick, m[1], &token))
+		assert.Equal(t, int64(4), token.StepID)
+		targets = append(targets, token.URL)
+	}
+	return targets
+}
+
+func TestRewriteHTMLLinks(t *testing.T) {
+	tracker := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"})
+	body := `<p><a href="https://example.com/pricing?a=1&amp;b=2">Pricing</a>, ` +
+		`<a class="x" HREF='http://example.com/docs'>docs</a>, ` +
+		`<a href="mailto:sales@example.com">mail us</a> or <a href="#top">top</a></p>`
+
+	rewritten, err := tracker.RewriteHTMLLinks(body, Token{StepID: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/pricing?a=1&b=2", "http://example.com/docs"}, clickTargets(t, tracker, rewritten))
+	assert.Contains(t, rewritten, `<a class="x" HREF='https://t.example.com/t/c/`)
+	assert.Contains(t, rewritten, `<a href="mailto:sales@example.com">`)
+	assert.Contains(t, rewritten, `<a href="#top">`)
+}
+
+func TestRewriteTextLinks(t *testing.T) {
+	tracker := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"})
+	body := "See https://example.com/pricing. Docs (http://example.com/docs) too."
+
+	rewritten, err := tracker.RewriteTextLinks(body, Token{StepID: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/pricing", "http://example.com/docs"}, clickTargets(t, tracker, rewritten))
+	assert.Regexp(t, `^See https://t\.example\.com/t/c/\S+\. Docs \(https://t\.example\.com/t/c/\S+\) too\.$`, rewritten)
+}
+
+func TestTrackable(t *testing.T) {
+	assert.True(t, Trackable("https://example.com"))
+	assert.True(t, Trackable("http://example.com/a?b=c"))
+	assert.False(t, Trackable("javascript:alert(1)"))
+	assert.False(t, Trackable("//example.com"))
+	assert.False(t, Trackable("/relative"))
+	assert.False(t, Trackable("mailto:sales@example.com"))
+}
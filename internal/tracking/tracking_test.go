@@ -0,0 +1,69 @@
+package tracking
+
+import (
+	"github.com/stretchr/testify/assert"
+	"salesforge-api/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	tracker := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"})
+	token := Token{AccountID: 1, SequenceID: 2, EnrollmentID: 3, StepID: 4}
+
+	signed, err := tracker.Sign(PurposeOpen, token)
+	assert.NoError(t, err)
+
+	var got Token
+	assert.NoError(t, tracker.Verify(PurposeOpen, signed, &got))
+	assert.Equal(t, token, got)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	tracker := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"})
+	signed, err := tracker.Sign(PurposeOpen, Token{AccountID: 1, EnrollmentID: 3, StepID: 4})
+	assert.NoError(t, err)
+	payload, signature, _ := strings.Cut(signed, ".")
+
+	other := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "other"})
+	forged, err := other.Sign(PurposeOpen, Token{AccountID: 2, EnrollmentID: 3, StepID: 4})
+	assert.NoError(t, err)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	click, err := tracker.Sign(PurposeClick, ClickToken{Token: Token{AccountID: 1, EnrollmentID: 3, StepID: 4}, URL: "https://example.com"})
+	assert.NoError(t, err)
+
+	tests := map[string]string{
+		"empty":              "",
+		"no signature":       payload,
+		"bad encoding":       payload + ".!!",
+		"other secret":       forged,
+		"click token":        click,
+		"swapped payload":    forgedPayload + "." + signature,
+		"truncated mac":      payload + "." + signature[:10],
+		"not a token at all": "hello.world",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got Token
+			assert.ErrorIs(t, tracker.Verify(PurposeOpen, token, &got), ErrInvalidToken)
+		})
+	}
+}
+
+func TestInjectPixel(t *testing.T) {
+	tracker := NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com/", Secret: "secret"})
+
+	body, err := tracker.InjectPixel("<p>Hi</p>", Token{StepID: 1})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body, `<p>Hi</p><img src="https://t.example.com/t/o/`))
+
+	body, err = tracker.InjectPixel("<HTML><BODY><p>Hi</p></BODY></HTML>", Token{StepID: 1})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(body, `style="display:none"></BODY></HTML>`))
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, NewTracker(config.TrackingConfig{}).Enabled())
+	assert.True(t, NewTracker(config.TrackingConfig{BaseURL: "https://t.example.com", Secret: "secret"}).Enabled())
+}